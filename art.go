@@ -0,0 +1,303 @@
+package packong
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultDPI is assumed for raster artwork telling no resolution
+var DefaultDPI = 72.0
+
+// inches in a meter
+const inchesPerMeter = 1 / 0.0254
+
+// Artwork is the printed size of an artwork file
+type Artwork struct {
+	Name string
+	// size in meters
+	W, H float64
+	// pixels and resolution of raster artwork; zero for vector artwork
+	Px, Py     int
+	DPIx, DPIy float64
+	// size is guessed as artwork tells no resolution or no size
+	Guessed bool
+}
+
+// IsArtwork tells by extension whether name is an artwork ReadArtwork reads
+func IsArtwork(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png", ".jpg", ".jpeg", ".svg":
+		return true
+	}
+	return false
+}
+
+// ReadArtwork reads printed size of a png, jpeg or svg artwork telling them apart by name's extension
+func ReadArtwork(name string, r io.Reader) (Artwork, error) {
+	var (
+		a   Artwork
+		err error
+	)
+	br := bufio.NewReader(r)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png":
+		a, err = readPNG(br)
+	case ".jpg", ".jpeg":
+		a, err = readJPEG(br)
+	case ".svg":
+		a, err = readSVG(br)
+	default:
+		return a, fmt.Errorf("artwork %q is neither png, jpeg nor svg", name)
+	}
+	if err != nil {
+		return a, fmt.Errorf("artwork %s: %v", name, err)
+	}
+	a.Name = name
+	return a, nil
+}
+
+// raster sizes pixels by resolution, falling back to DefaultDPI
+func (a *Artwork) raster(px, py int, dpix, dpiy float64) {
+	a.Px, a.Py = px, py
+	if dpix <= 0 || dpiy <= 0 {
+		dpix, dpiy, a.Guessed = DefaultDPI, DefaultDPI, true
+	}
+	a.DPIx, a.DPIy = dpix, dpiy
+	a.W, a.H = float64(px)/dpix/inchesPerMeter, float64(py)/dpiy/inchesPerMeter
+}
+
+// Spec gives a piece as large as artwork, in unit u, scaled by scale, printing it;
+// zero scale keeps artwork's size and label is its name without extension
+func (a Artwork) Spec(u Unit, scale float64) PieceSpec {
+	if scale <= 0 {
+		scale = 1
+	}
+	// hundredths of unit are enough
+	f := func(v float64) float64 {
+		return math.Round(v*scale*u.PerMeter*100) / 100
+	}
+	base := filepath.Base(a.Name)
+	return PieceSpec{
+		W:      f(a.W),
+		H:      f(a.H),
+		Qty:    1,
+		Rotate: true,
+		Label:  strings.TrimSuffix(base, filepath.Ext(base)),
+		Art:    a.Name,
+	}
+}
+
+func readPNG(r io.Reader) (Artwork, error) {
+	var a Artwork
+	sig := make([]byte, 8)
+	if _, err := io.ReadFull(r, sig); err != nil || string(sig) != "\x89PNG\r\n\x1a\n" {
+		return a, errors.New("not a png")
+	}
+	var (
+		px, py     int
+		dpix, dpiy float64
+	)
+	for {
+		var head [8]byte
+		if _, err := io.ReadFull(r, head[:]); err != nil {
+			return a, errors.New("png ends before image data")
+		}
+		n, kind := binary.BigEndian.Uint32(head[:4]), string(head[4:])
+		if kind == "IDAT" || kind == "IEND" {
+			break
+		}
+		if n > 1<<24 {
+			return a, fmt.Errorf("png chunk %s too large", kind)
+		}
+		// chunk's crc is read along
+		data := make([]byte, n+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return a, err
+		}
+		switch {
+		case kind == "IHDR" && n >= 8:
+			px, py = int(binary.BigEndian.Uint32(data)), int(binary.BigEndian.Uint32(data[4:]))
+		case kind == "pHYs" && n >= 9 && data[8] == 1:
+			// pixels per meter
+			dpix = float64(binary.BigEndian.Uint32(data)) / inchesPerMeter
+			dpiy = float64(binary.BigEndian.Uint32(data[4:])) / inchesPerMeter
+		}
+	}
+	if px == 0 || py == 0 {
+		return a, errors.New("png has no size")
+	}
+	a.raster(px, py, dpix, dpiy)
+	return a, nil
+}
+
+func readJPEG(r io.Reader) (Artwork, error) {
+	var a Artwork
+	var soi [2]byte
+	if _, err := io.ReadFull(r, soi[:]); err != nil || soi != [2]byte{0xff, 0xd8} {
+		return a, errors.New("not a jpeg")
+	}
+	var dpix, dpiy float64
+	for {
+		var m [4]byte
+		if _, err := io.ReadFull(r, m[:2]); err != nil {
+			return a, errors.New("jpeg ends before its frame")
+		}
+		// markers may be padded by 0xff
+		for m[0] == 0xff && m[1] == 0xff {
+			if _, err := io.ReadFull(r, m[1:2]); err != nil {
+				return a, err
+			}
+		}
+		if m[0] != 0xff {
+			return a, errors.New("jpeg marker expected")
+		}
+		if _, err := io.ReadFull(r, m[2:]); err != nil {
+			return a, err
+		}
+		n := int(binary.BigEndian.Uint16(m[2:])) - 2
+		if n < 0 {
+			return a, errors.New("jpeg segment has bad length")
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return a, err
+		}
+		switch marker := m[1]; {
+		case marker == 0xe0 && n >= 12 && string(data[:5]) == "JFIF\x00":
+			x, y := float64(binary.BigEndian.Uint16(data[8:])), float64(binary.BigEndian.Uint16(data[10:]))
+			switch data[7] {
+			case 1: // dots per inch
+				dpix, dpiy = x, y
+			case 2: // dots per centimeter
+				dpix, dpiy = x*2.54, y*2.54
+			}
+		case marker >= 0xc0 && marker <= 0xcf && marker != 0xc4 && marker != 0xc8 && marker != 0xcc:
+			// start of frame tells size
+			if n < 5 {
+				return a, errors.New("jpeg frame too short")
+			}
+			py, px := int(binary.BigEndian.Uint16(data[1:])), int(binary.BigEndian.Uint16(data[3:]))
+			if px == 0 || py == 0 {
+				return a, errors.New("jpeg has no size")
+			}
+			a.raster(px, py, dpix, dpiy)
+			return a, nil
+		}
+	}
+}
+
+// svg lengths in meters of a unit; a px is a 96th of an inch
+var svgUnits = map[string]float64{
+	"":   0.0254 / 96,
+	"px": 0.0254 / 96,
+	"pt": 0.0254 / 72,
+	"pc": 0.0254 / 6,
+	"in": 0.0254,
+	"cm": 0.01,
+	"mm": 0.001,
+	"q":  0.00025,
+}
+
+func readSVG(r io.Reader) (Artwork, error) {
+	var a Artwork
+	d := xml.NewDecoder(r)
+	// entities and doctypes of svg files are not needed
+	d.Strict = false
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return a, errors.New("no svg element found")
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if se.Name.Local != "svg" {
+			return a, fmt.Errorf("root element is %s, not svg", se.Name.Local)
+		}
+		var width, height, viewBox string
+		for _, at := range se.Attr {
+			switch at.Name.Local {
+			case "width":
+				width = at.Value
+			case "height":
+				height = at.Value
+			case "viewBox":
+				viewBox = at.Value
+			}
+		}
+		return svgSize(width, height, viewBox)
+	}
+}
+
+// svgSize works out printed size from width, height and viewBox of svg element
+func svgSize(width, height, viewBox string) (Artwork, error) {
+	var a Artwork
+	// user units of viewBox
+	var vw, vh float64
+	if viewBox != "" {
+		ff := strings.FieldsFunc(viewBox, func(c rune) bool { return c == ',' || c == ' ' || c == '\t' || c == '\n' })
+		if len(ff) != 4 {
+			return a, fmt.Errorf("viewBox %q needs 4 numbers", viewBox)
+		}
+		var err error
+		if vw, err = strconv.ParseFloat(ff[2], 64); err != nil {
+			return a, fmt.Errorf("viewBox %q: %v", viewBox, err)
+		}
+		if vh, err = strconv.ParseFloat(ff[3], 64); err != nil {
+			return a, fmt.Errorf("viewBox %q: %v", viewBox, err)
+		}
+	}
+	length := func(s string) (float64, bool, error) {
+		s = strings.ToLower(strings.TrimSpace(s))
+		// percents relate to a viewport svg has none of
+		if s == "" || strings.HasSuffix(s, "%") {
+			return 0, false, nil
+		}
+		i := strings.IndexFunc(s, func(c rune) bool { return c >= 'a' && c <= 'z' })
+		num, unit := s, ""
+		if i >= 0 {
+			num, unit = s[:i], s[i:]
+		}
+		m, ok := svgUnits[unit]
+		if !ok {
+			return 0, false, fmt.Errorf("length %q has unknown unit", s)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+		if err != nil || v <= 0 {
+			return 0, false, fmt.Errorf("length %q is not a positive number", s)
+		}
+		return v * m, true, nil
+	}
+	w, okw, err := length(width)
+	if err != nil {
+		return a, err
+	}
+	h, okh, err := length(height)
+	if err != nil {
+		return a, err
+	}
+	switch {
+	case okw && okh:
+	case vw <= 0 || vh <= 0:
+		return a, errors.New("svg tells neither width and height nor viewBox")
+	case okw:
+		h = w * vh / vw
+	case okh:
+		w = h * vw / vh
+	default:
+		// user units are taken as px
+		w, h = vw*svgUnits["px"], vh*svgUnits["px"]
+		a.Guessed = true
+	}
+	a.W, a.H = w, h
+	return a, nil
+}
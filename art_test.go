@@ -0,0 +1,73 @@
+package packong
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestReadArtwork(t *testing.T) {
+	chunk := func(kind string, data []byte) []byte {
+		b := make([]byte, 4, 12+len(data))
+		binary.BigEndian.PutUint32(b, uint32(len(data)))
+		b = append(b, kind...)
+		b = append(b, data...)
+		// crc is not checked
+		return append(b, 0, 0, 0, 0)
+	}
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr, 3000)
+	binary.BigEndian.PutUint32(ihdr[4:], 1500)
+	// 300 dpi as pixels per meter
+	phys := make([]byte, 9)
+	binary.BigEndian.PutUint32(phys, 11811)
+	binary.BigEndian.PutUint32(phys[4:], 11811)
+	phys[8] = 1
+	png := []byte("\x89PNG\r\n\x1a\n")
+	png = append(png, chunk("IHDR", ihdr)...)
+	png = append(png, chunk("pHYs", phys)...)
+	png = append(png, chunk("IEND", nil)...)
+
+	jpeg := []byte{0xff, 0xd8,
+		// JFIF, 2 dots per centimeter
+		0xff, 0xe0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 1, 2, 0, 100, 0, 100, 0, 0,
+		// baseline frame of 500 by 250
+		0xff, 0xc0, 0, 11, 8, 0, 250, 0x01, 0xf4, 1, 1, 0x11, 0}
+
+	mm, _ := LookupUnit("mm")
+	tt := []struct {
+		name    string
+		data    []byte
+		w, h    float64
+		guessed bool
+	}{
+		{"poster.png", png, 254, 127, false},
+		{"logo.JPG", jpeg, 50, 25, false},
+		{"a4.svg", []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg" width="210mm" height="297mm"></svg>`), 210, 297, false},
+		{"half.svg", []byte(`<svg width="4in" viewBox="0 0 400 200"></svg>`), 101.6, 50.8, false},
+		{"box.svg", []byte(`<svg viewBox="0,0,96,48"></svg>`), 25.4, 12.7, true},
+	}
+	for _, tc := range tt {
+		a, err := ReadArtwork(tc.name, bytes.NewReader(tc.data))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		ps := a.Spec(mm, 0)
+		if ps.W != tc.w || ps.H != tc.h || a.Guessed != tc.guessed {
+			t.Errorf("%s: got %vx%v guessed %v, expected %vx%v guessed %v", tc.name, ps.W, ps.H, a.Guessed, tc.w, tc.h, tc.guessed)
+		}
+	}
+
+	a, _ := ReadArtwork("poster.png", bytes.NewReader(png))
+	if ps := a.Spec(mm, 0.5); ps.String() != "127x63.5 label=poster art=poster.png" {
+		t.Errorf("got %q, expected half sized poster", ps.String())
+	}
+
+	for name, data := range map[string]string{"x.png": "GIF89a", "x.svg": "<html></html>", "x.gif": ""} {
+		if _, err := ReadArtwork(name, strings.NewReader(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package packong
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// default name of a tape when none is given
+const defaultTape = "tape"
+
+// Banding tells which edges of a piece get edge tape;
+// top and bottom edges run along width, left and right ones along height
+type Banding struct {
+	Top    bool `json:"top"`
+	Right  bool `json:"right"`
+	Bottom bool `json:"bottom"`
+	Left   bool `json:"left"`
+	// tape thickness is taken out of cut size
+	Thickness float64 `json:"thickness"`
+	Tape      string  `json:"tape"`
+}
+
+// parseBanding reads "edges[:thickness[:tape]]" where edges is made of
+// letters t, r, b, l; for instance "tb:0.8:abs"
+func parseBanding(s string) (*Banding, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("banding %q has too many parts", s)
+	}
+
+	b := &Banding{Tape: defaultTape}
+	if parts[0] == "" {
+		return nil, fmt.Errorf("banding %q has no edges", s)
+	}
+	for _, c := range parts[0] {
+		switch c {
+		case 't':
+			b.Top = true
+		case 'r':
+			b.Right = true
+		case 'b':
+			b.Bottom = true
+		case 'l':
+			b.Left = true
+		default:
+			return nil, fmt.Errorf("banding %q has unknown edge %q", s, c)
+		}
+	}
+
+	if len(parts) > 1 {
+		t, err := parseLength(parts[1])
+		if err != nil {
+			return nil, err
+		} else if t < 0 {
+			return nil, fmt.Errorf("banding thickness must not be negative; received %v", t)
+		}
+		b.Thickness = t
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		b.Tape = parts[2]
+	}
+
+	return b, nil
+}
+
+// String writes banding as parseBanding reads it
+func (b *Banding) String() string {
+	s := ""
+	for _, e := range []struct {
+		on     bool
+		letter string
+	}{{b.Top, "t"}, {b.Right, "r"}, {b.Bottom, "b"}, {b.Left, "l"}} {
+		if e.on {
+			s += e.letter
+		}
+	}
+	if b.Thickness != 0 || b.Tape != defaultTape {
+		s += ":" + strconv.FormatFloat(b.Thickness, 'f', -1, 64)
+	}
+	if b.Tape != defaultTape {
+		s += ":" + b.Tape
+	}
+	return s
+}
+
+func count(edges ...bool) float64 {
+	n := 0.0
+	for _, e := range edges {
+		if e {
+			n++
+		}
+	}
+	return n
+}
+
+// cutSize gives dimensions a piece must be cut at so that it reaches w, h once banded
+func (b *Banding) cutSize(w, h float64) (float64, float64) {
+	if b == nil {
+		return w, h
+	}
+	return w - b.Thickness*count(b.Left, b.Right), h - b.Thickness*count(b.Top, b.Bottom)
+}
+
+// length of tape needed by a piece w wide and h high
+func (b *Banding) length(w, h float64) float64 {
+	if b == nil {
+		return 0.0
+	}
+	return w*count(b.Top, b.Bottom) + h*count(b.Left, b.Right)
+}
+
+// edges gives banded edges as they lay on sheet; a rotated piece is turned
+// clockwise so its top edge ends on the right
+func (b *Banding) edges(rotated bool) (top, right, bottom, left bool) {
+	if rotated {
+		return b.Left, b.Top, b.Right, b.Bottom
+	}
+	return b.Top, b.Right, b.Bottom, b.Left
+}
+
+// bandedEdges gives lines along every banded edge of boxes on sheet
+func (op *Op) bandedEdges(sh sheet) [][4]float64 {
+	lines := [][4]float64{}
+	for _, box := range sh.boxes {
+		pc := sh.pieces[box]
+		if pc == nil || pc.Banding == nil {
+			continue
+		}
+		// cut width is not part of the piece
+		x, y := box.X, box.Y
+		w, h := box.W-0.5*op.cutwidth, box.H-0.5*op.cutwidth
+		top, right, bottom, left := pc.Banding.edges(box.Rotated)
+		if top {
+			lines = append(lines, [4]float64{x, y, x + w, y})
+		}
+		if right {
+			lines = append(lines, [4]float64{x + w, y, x + w, y + h})
+		}
+		if bottom {
+			lines = append(lines, [4]float64{x, y + h, x + w, y + h})
+		}
+		if left {
+			lines = append(lines, [4]float64{x, y, x, y + h})
+		}
+	}
+	return lines
+}
@@ -0,0 +1,42 @@
+package packong
+
+import (
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestBandingStroke(t *testing.T) {
+	// inches are few, so banded edges are drawn thin
+	op := NewOp(48, 96, []string{"24x36 band=tb"}, "in").Outname("x")
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, outs, err := op.Fit([][]*pak.Box{boxes}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stroke := regexp.MustCompile(`stroke:orange;stroke-width:([0-9.]+)`)
+	n := 0
+	for _, out := range outs {
+		for _, r := range out {
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range stroke.FindAllStringSubmatch(string(b), -1) {
+				n++
+				if sw, _ := strconv.ParseFloat(m[1], 64); sw > 48.0/200 {
+					t.Errorf("got banding %v thick on a sheet 48 wide", sw)
+				}
+			}
+		}
+	}
+	if n != 2 {
+		t.Errorf("got %d banded edges drawn, expected 2", n)
+	}
+}
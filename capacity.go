@@ -0,0 +1,379 @@
+package packong
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/innermond/pak"
+)
+
+// Capacity tells how many pieces fit sheets, quantities asked for being ratios
+type Capacity struct {
+	// sheets pieces are fit on
+	Sheets int `json:"sheets"`
+	// how many times ratios fit
+	Multiple int `json:"multiple"`
+	// pieces of every dimension entry, in their order, and all of them
+	Qty   []int `json:"qty"`
+	Total int   `json:"total"`
+}
+
+// HowMany packs as many pieces as sheets hold, keeping quantities of dimensions as ratios;
+// a single kind of rectangle fills sheets by guillotine blocks turning some pieces,
+// while several kinds are packed for growing multiples of their ratios
+func (op *Op) HowMany(sheets int) (*Report, []FitReader, error) {
+	if sheets < 1 {
+		return nil, nil, fmt.Errorf("how many fit needs one sheet at least; received %d", sheets)
+	}
+	if err := over("sheets", float64(op.limits.MaxSheets), float64(sheets)); err != nil {
+		return nil, nil, err
+	}
+	// ratios are checked as any dimensions
+	if _, err := op.BoxesFromString(); err != nil {
+		return nil, nil, err
+	}
+	ratios := op.dimensions
+	defer func() {
+		op.dimensions, op.fill = ratios, false
+	}()
+	specs := make([]PieceSpec, len(ratios))
+	// area sheets have for pieces and area of pieces for a multiple
+	rw, rh := op.room()
+	room, area := float64(sheets)*rw*rh, 0.0
+	for i, dd := range ratios {
+		specs[i], _ = ParseSpec(dd)
+		a := specs[i].W * specs[i].H
+		if specs[i].Form != FormRect {
+			a *= math.Pi / 4
+		}
+		area += a * float64(specs[i].Qty)
+	}
+	op.fill = len(specs) == 1 && specs[0].Form == FormRect
+
+	// try tells whether m times ratios fit, with report and drawings when they do
+	var (
+		limited  = math.MaxInt32
+		limitErr error
+	)
+	try := func(m int) (*Report, []FitReader, error) {
+		dd := make([]string, len(specs))
+		for i, ps := range specs {
+			ps.Qty *= m
+			dd[i] = ps.String()
+		}
+		op.dimensions = dd
+		boxes, err := op.BoxesFromString()
+		if err == nil {
+			var (
+				rep  *Report
+				outs []FitReader
+			)
+			rep, outs, err = op.Fit([][]*pak.Box{boxes}, false)
+			if err == nil {
+				if rep.UnfitLen > 0 || rep.NumSheetUsed > float64(sheets) {
+					return nil, nil, nil
+				}
+				return rep, outs, nil
+			}
+		}
+		if le, ok := err.(*LimitError); ok {
+			// more sheets than asked for just do not fit
+			if le.Limit != "sheets" && m < limited {
+				limited, limitErr = m, err
+			}
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	var (
+		rep  *Report
+		outs []FitReader
+	)
+	// doubling finds a multiple too many, halving closes in on the largest fitting;
+	// no multiple goes past what area of sheets allows
+	most := int(room / area)
+	lo, hi := 0, 1
+	if op.fill {
+		// a block holds all a sheet takes, so the most fitting is known
+		ps := specs[0]
+		bleed := ps.Bleed
+		if bleed == 0 {
+			bleed = op.bleed
+		}
+		cw, ch := ps.Banding.cutSize(ps.W, ps.H)
+		bw, bh := cw+2*bleed+op.cutwidth+op.gutter, ch+2*bleed+op.cutwidth+op.gutter
+		if n := len(dense(rw+op.gutter, rh+op.gutter, bw, bh, ps.Rotate).at) * sheets / ps.Qty; n > 0 && n <= most {
+			r, o, err := try(n)
+			if err != nil {
+				return nil, nil, err
+			}
+			if r != nil {
+				rep, outs, lo, hi = r, o, n, n+1
+			}
+		}
+	}
+	if rep == nil {
+		for hi <= most {
+			r, o, err := try(hi)
+			if err != nil {
+				return nil, nil, err
+			}
+			if r == nil {
+				break
+			}
+			rep, outs, lo = r, o, hi
+			hi *= 2
+		}
+		if hi > most+1 {
+			hi = most + 1
+		}
+	}
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		r, o, err := try(mid)
+		if err != nil {
+			return nil, nil, err
+		}
+		if r == nil {
+			hi = mid
+			continue
+		}
+		rep, outs, lo = r, o, mid
+	}
+	if rep == nil {
+		if limitErr != nil {
+			// limits tell why better than fitting does
+			return nil, nil, limitErr
+		}
+		return nil, nil, errors.New("no pieces fit sheets")
+	}
+
+	c := &Capacity{Sheets: sheets, Multiple: lo}
+	for _, ps := range specs {
+		c.Qty = append(c.Qty, ps.Qty*lo)
+		c.Total += ps.Qty * lo
+	}
+	if limited == lo+1 {
+		rep.Warnings = append(rep.Warnings, "more pieces may fit than limits allow")
+	}
+	rep.Capacity = c
+	return rep, outs, nil
+}
+
+// filled groups qty boxes w by h, spacing included, into blocks filling a sheet's room,
+// as many as straight cuts through it can part; turn lets some boxes lay turned
+func (op *Op) filled(w, h float64, turn bool, qty int) []block {
+	rw, rh := op.room()
+	// blocks are packed like boxes, gutter included
+	full := dense(rw+op.gutter, rh+op.gutter, w, h, turn)
+	var blocks []block
+	for len(full.at) > 0 && qty > 0 {
+		n := len(full.at)
+		if qty < n {
+			n = qty
+		}
+		blocks = append(blocks, full.first(n, w, h))
+		qty -= n
+	}
+	return blocks
+}
+
+// first keeps n pieces, w by h, of a block, those nearest top then left, in a block just holding them
+func (bl block) first(n int, w, h float64) block {
+	b := block{}
+	for i := 0; i < n; i++ {
+		pw, ph := w, h
+		if bl.turned[i] {
+			pw, ph = h, w
+		}
+		b.at = append(b.at, bl.at[i])
+		b.turned = append(b.turned, bl.turned[i])
+		b.w, b.h = math.Max(b.w, bl.at[i][0]+pw), math.Max(b.h, bl.at[i][1]+ph)
+	}
+	return b
+}
+
+// past this many steps guillotine cuts are tried only once, parting plain grids
+const guillotineSteps = 4e6
+
+// guillotine works out how rooms hold most boxes w by h when cut straight through,
+// edge to edge, again and again; rooms are sized by sums of boxes' sides as
+// sizes in between hold nothing more
+type guillotine struct {
+	w, h   float64
+	turn   bool
+	xs, ys []float64
+	best   map[[2]int]cut
+}
+
+// cut is how many boxes a room xs[i] by ys[j] holds, split across its width at xs[at],
+// across its height at ys[at], or not at all, boxes laying in a grid
+type cut struct {
+	n      int
+	across byte
+	at     int
+}
+
+// dense lays as many boxes w by h as fit a room rw by rh cut by guillotine,
+// some turned by a quarter when turn is set; pieces come ordered top then left
+func dense(rw, rh, w, h float64, turn bool) block {
+	g := &guillotine{w: w, h: h, turn: turn, best: map[[2]int]cut{}}
+	if turn && w != h {
+		g.xs, g.ys = sizes(rw, w, h), sizes(rh, w, h)
+	} else {
+		g.xs, g.ys = sizes(rw, w), sizes(rh, h)
+	}
+	i, j := index(g.xs, rw), index(g.ys, rh)
+	if i < 0 || j < 0 {
+		return block{}
+	}
+	if nx, ny := float64(len(g.xs)), float64(len(g.ys)); nx*ny*(nx+ny) <= guillotineSteps {
+		// smaller rooms first, as larger ones are made of them
+		for a := 0; a <= i; a++ {
+			for b := 0; b <= j; b++ {
+				g.solve(a, b)
+			}
+		}
+	} else {
+		g.solve(i, j)
+	}
+
+	bl := block{}
+	g.lay(i, j, 0, 0, &bl)
+	order := make([]int, len(bl.at))
+	for k := range order {
+		order[k] = k
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		pa, pb := bl.at[order[a]], bl.at[order[b]]
+		if pa[1] != pb[1] {
+			return pa[1] < pb[1]
+		}
+		return pa[0] < pb[0]
+	})
+	sorted := block{w: bl.w, h: bl.h}
+	for _, k := range order {
+		sorted.at = append(sorted.at, bl.at[k])
+		sorted.turned = append(sorted.turned, bl.turned[k])
+	}
+	return sorted
+}
+
+// grid tells how many boxes a room x by y holds in rows and whether they are turned
+func (g *guillotine) grid(x, y float64) (int, bool) {
+	n := int(math.Floor(x/g.w+1e-9)) * int(math.Floor(y/g.h+1e-9))
+	if !g.turn {
+		return n, false
+	}
+	if t := int(math.Floor(x/g.h+1e-9)) * int(math.Floor(y/g.w+1e-9)); t > n {
+		return t, true
+	}
+	return n, false
+}
+
+// count tells how many boxes room i by j holds, as far as it is solved
+func (g *guillotine) count(i, j int) int {
+	if i < 0 || j < 0 {
+		return 0
+	}
+	if c, ok := g.best[[2]int{i, j}]; ok {
+		return c.n
+	}
+	n, _ := g.grid(g.xs[i], g.ys[j])
+	return n
+}
+
+// solve finds best cut of room i by j; cuts past half of room repeat those before it
+func (g *guillotine) solve(i, j int) {
+	x, y := g.xs[i], g.ys[j]
+	best := cut{}
+	best.n, _ = g.grid(x, y)
+	for k := 0; k < i && g.xs[k] <= x/2+1e-9; k++ {
+		if n := g.count(k, j) + g.count(index(g.xs, x-g.xs[k]), j); n > best.n {
+			best = cut{n, 'x', k}
+		}
+	}
+	for k := 0; k < j && g.ys[k] <= y/2+1e-9; k++ {
+		if n := g.count(i, k) + g.count(i, index(g.ys, y-g.ys[k])); n > best.n {
+			best = cut{n, 'y', k}
+		}
+	}
+	g.best[[2]int{i, j}] = best
+}
+
+// lay places boxes of room i by j, its top left corner at x, y, into bl
+func (g *guillotine) lay(i, j int, x, y float64, bl *block) {
+	if i < 0 || j < 0 {
+		return
+	}
+	c, ok := g.best[[2]int{i, j}]
+	switch {
+	case ok && c.across == 'x':
+		g.lay(c.at, j, x, y, bl)
+		g.lay(index(g.xs, g.xs[i]-g.xs[c.at]), j, x+g.xs[c.at], y, bl)
+	case ok && c.across == 'y':
+		g.lay(i, c.at, x, y, bl)
+		g.lay(i, index(g.ys, g.ys[j]-g.ys[c.at]), x, y+g.ys[c.at], bl)
+	default:
+		_, turned := g.grid(g.xs[i], g.ys[j])
+		w, h := g.w, g.h
+		if turned {
+			w, h = h, w
+		}
+		cols, rows := int(math.Floor(g.xs[i]/w+1e-9)), int(math.Floor(g.ys[j]/h+1e-9))
+		for r := 0; r < rows; r++ {
+			for c := 0; c < cols; c++ {
+				bl.at = append(bl.at, [2]float64{x + float64(c)*w, y + float64(r)*h})
+				bl.turned = append(bl.turned, turned)
+				bl.w, bl.h = math.Max(bl.w, x+float64(c+1)*w), math.Max(bl.h, y+float64(r+1)*h)
+			}
+		}
+	}
+}
+
+// sizes gives every sum of sides' multiples up to max, ascending and but zero
+func sizes(max float64, sides ...float64) []float64 {
+	vv := []float64{0}
+	for _, s := range sides {
+		for i := 0; i < len(vv); i++ {
+			if v := vv[i] + s; v <= max+1e-9 {
+				vv = append(vv, v)
+			}
+		}
+	}
+	sort.Float64s(vv)
+	ss := []float64{}
+	for _, v := range vv[1:] {
+		if len(ss) == 0 || v-ss[len(ss)-1] > 1e-9 {
+			ss = append(ss, v)
+		}
+	}
+	return ss
+}
+
+// index gives position of largest size not over v, -1 when all are
+func index(ss []float64, v float64) int {
+	return sort.Search(len(ss), func(i int) bool { return ss[i] > v+1e-9 }) - 1
+}
+
+// opened gives a sheet whose blocks of rectangles filling it stand for their pieces, one box each
+func (op *Op) opened(sh sheet) sheet {
+	out := sheet{pieces: map[*pak.Box]*Piece{}, length: sh.length, boxesArea: sh.boxesArea}
+	for _, box := range sh.boxes {
+		pc := sh.pieces[box]
+		if pc == nil || pc.stagger == nil || pc.stagger.pieces[0].Form != FormRect {
+			out.boxes = append(out.boxes, box)
+			out.pieces[box] = pc
+			continue
+		}
+		for _, pl := range placements(pc, box, 0) {
+			b := &pak.Box{X: pl.X, Y: pl.Y, W: pl.W, H: pl.H, Rotated: pl.Rotated}
+			out.boxes = append(out.boxes, b)
+			out.pieces[b] = pl.Piece
+		}
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package packong
+
+import "testing"
+
+func TestHowMany(t *testing.T) {
+	howMany := func(l Limits, sheets int, dd ...string) *Report {
+		op := NewOp(330, 480, dd, "mm").VendorSellInt(false).Limits(l)
+		rep, _, err := op.HowMany(sheets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, a := range rep.Layout {
+			for _, b := range rep.Layout[i+1:] {
+				if a.Sheet == b.Sheet && a.X < b.X+b.W && b.X < a.X+a.W && a.Y < b.Y+b.H && b.Y < a.Y+a.H {
+					t.Fatalf("%v: %+v overlaps %+v", dd, a, b)
+				}
+			}
+		}
+		return rep
+	}
+
+	// neither grid holds more than 30 cards
+	rep := howMany(DefaultLimits, 1, "90x50")
+	if c := rep.Capacity; c.Total != 33 || len(rep.Layout) != 33 {
+		t.Errorf("got %+v, expected 33 cards", c)
+	}
+	turned := 0
+	for _, pl := range rep.Layout {
+		if pl.Rotated {
+			turned++
+		}
+	}
+	if turned == 0 || turned == len(rep.Layout) {
+		t.Errorf("got %d of %d cards turned, expected both orientations", turned, len(rep.Layout))
+	}
+	if c := howMany(Limits{}, 3, "90x50 qty=2").Capacity; c.Total != 98 || c.Multiple != 49 {
+		t.Errorf("got %+v on 3 sheets, expected 49 times 2 cards", c)
+	}
+	if rep := howMany(DefaultLimits, 3, "90x50"); rep.Capacity.Total != 50 || len(rep.Warnings) != 1 {
+		t.Errorf("got %+v warning %v, expected 50 cards as qty limit allows", rep.Capacity, rep.Warnings)
+	}
+
+	rep = howMany(DefaultLimits, 1, "90x50 qty=2", "60x60")
+	c := rep.Capacity
+	if c.Qty[0] != 2*c.Qty[1] || c.Total != len(rep.Layout) || c.Total < 20 {
+		t.Errorf("got %+v, expected twice as many cards as squares", c)
+	}
+
+	op := NewOp(330, 480, []string{"500x50"}, "mm")
+	if _, _, err := op.HowMany(1); err == nil {
+		t.Error("expected error as no piece fits")
+	}
+	if _, _, err := op.HowMany(0); err == nil {
+		t.Error("expected error for no sheets")
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -86,18 +87,25 @@ func fitboxes(w http.ResponseWriter, r *http.Request) {
 
 	// get input data
 	var resp ResponseData
+	// uploaded artwork by name
+	art := map[string][]byte{}
 	{
-		dec := json.NewDecoder(r.Body)
-		fail := dec.Decode(&resp)
+		fail := decodeInput(r, &resp, art)
 		var (
 			msg  string
 			code int
 		)
 		if fail != nil {
-			switch fail.(type) {
+			switch x := fail.(type) {
+			case packong.CutListError:
+				wlist(w, x)
+				return
 			case *json.SyntaxError:
 				msg = "json syntax malformation"
 				code = 400 // bad request
+			case *csv.ParseError:
+				msg = "csv syntax malformation"
+				code = 400
 			default:
 				msg = "invalid data"
 				code = 422 // unprocessable entity
@@ -139,10 +147,14 @@ func fitboxes(w http.ResponseWriter, r *http.Request) {
 	}
 
 	dimensions = resp.Dimensions
-	if len(dimensions) == 0 {
+	if len(dimensions) == 0 && len(resp.Shapes) == 0 {
 		werr(w, err.text("fitboxes: dimensions required"), 422, "dimensions required")
 		return
 	}
+	if resp.HowMany < 0 {
+		werr(w, err.text("fitboxes: how_many below zero"), 422, "how_many needs a number of sheets")
+		return
+	}
 
 	op := packong.NewOp(width, height, dimensions, unit).
 		Outname(outname).
@@ -150,16 +162,140 @@ func fitboxes(w http.ResponseWriter, r *http.Request) {
 		Tight(tight).
 		Topleft(topleftmargin).
 		Cutwidth(cutwidth).
+		Gutter(resp.Gutter).
 		Appearance(plain, showDim, true).
-		Price(mu, ml, pp, pd)
-
-	boxes, fail := op.BoxesFromString()
-	if fail != nil {
-		werr(w, err.from(fail), 422, "couldn't figure out dimensions; invalid dimensions")
+		Price(mu, ml, pp, pd).
+		BandingPrice(resp.Pb).
+		Labour(resp.Ph).
+		Material(resp.Material).
+		Legend(resp.Legend).
+		Limits(limits)
+	if resp.Title != "" {
+		op.Title(resp.Title, resp.Material)
+	}
+	if resp.Margins != (packong.Margins{}) {
+		op.Margins(resp.Margins)
+	}
+	op.Bleed(resp.Bleed)
+	if resp.Tile {
+		op.Tiling(resp.Overlap)
+	}
+	if resp.Marks != (packong.Marks{}) {
+		switch resp.Marks.Reg {
+		case "", packong.RegSquare, packong.RegCircle:
+		default:
+			werr(w, err.text("fitboxes: unknown registration mark "+resp.Marks.Reg), 422, "registration mark is neither square nor circle")
+			return
+		}
+		op.Marks(resp.Marks)
+	}
+	switch resp.Impose {
+	case "":
+	case "embed", "link":
+		// server files are never read
+		op.Imposition(resp.Impose == "embed", uploaded(art))
+	default:
+		werr(w, err.text("fitboxes: unknown imposition "+resp.Impose), 422, "impose is neither embed nor link")
+		return
+	}
+	if priceList != nil {
+		op.PriceList(priceList, resp.Group)
+	}
+	if resp.TargetMargin > 0 {
+		if resp.TargetMargin >= 100 && resp.ManualPrice <= 0 {
+			werr(w, err.text("fitboxes: target margin not below 100"), 422, "target margin leaves no price; it must be below 100")
+			return
+		}
+		op.TargetMargin(resp.TargetMargin)
+	}
+	if resp.ManualPrice > 0 {
+		op.ManualPrice(resp.ManualPrice)
+	}
+	if resp.Currency != "" {
+		if _, found := rates[resp.Currency]; !found {
+			werr(w, err.text("fitboxes: unknown currency "+resp.Currency), 422, "unknown currency")
+			return
+		}
+		op.Currency(resp.Currency, rates)
+	}
+	if resp.Vat < 0 {
+		werr(w, err.text("fitboxes: vat below zero"), 422, "vat is below zero")
 		return
 	}
+	op.VAT(resp.Vat)
+	{
+		rp := packong.RoundingPolicy{Step: resp.RoundStep}
+		var fail error
+		if resp.RoundLine != "" {
+			rp.Line, fail = packong.ParseRounding(resp.RoundLine)
+		}
+		if fail == nil && resp.RoundTotal != "" {
+			rp.Total, fail = packong.ParseRounding(resp.RoundTotal)
+		}
+		if fail != nil {
+			werr(w, err.from(fail), 422, "invalid rounding")
+			return
+		}
+		op.Rounding(rp)
+	}
+	// zero sizes keep defaults; others must make a range
+	if resp.FontMin != 0 || resp.FontMax != 0 {
+		if resp.FontMin <= 0 || resp.FontMax < resp.FontMin {
+			werr(w, err.text("fitboxes: font_min and font_max are not a range"), 422, "font_min and font_max need 0 < font_min <= font_max")
+			return
+		}
+		op.DimFont(resp.FontMin, resp.FontMax)
+	}
 
-	rep, outs, fail := op.Fit([][]*pak.Box{boxes}, false)
+	var (
+		rep  *packong.Report
+		outs []packong.FitReader
+		fail error
+	)
+	if len(resp.Shapes) > 0 {
+		shapes := []packong.Shape{}
+		for _, s := range resp.Shapes {
+			sh, fail := packong.ParseShape(s)
+			if fail != nil {
+				werr(w, err.from(fail), 422, "invalid shape")
+				return
+			}
+			shapes = append(shapes, sh)
+		}
+		rep, outs, fail = op.Nest(shapes, packong.Nesting{Step: resp.Step, Spacing: resp.Spacing})
+	} else {
+		var boxes []*pak.Box
+		boxes, fail = op.BoxesFromString()
+		if ve, ok := fail.(packong.ValidationError); ok {
+			if debug {
+				log.Printf("%v\t%v\n", rid, ve)
+			}
+			wlist(w, ve)
+			return
+		}
+		if le, ok := fail.(*packong.LimitError); ok {
+			werr(w, err.from(fail), limitStatus(le), le.Error())
+			return
+		}
+		if fail != nil {
+			werr(w, err.from(fail), 422, "couldn't figure out dimensions; invalid dimensions")
+			return
+		}
+
+		if resp.HowMany > 0 {
+			rep, outs, fail = op.HowMany(resp.HowMany)
+			if _, ok := fail.(*packong.LimitError); !ok && fail != nil {
+				werr(w, err.from(fail), 422, "couldn't tell how many pieces fit")
+				return
+			}
+		} else {
+			rep, outs, fail = op.Fit([][]*pak.Box{boxes}, false)
+		}
+	}
+	if le, ok := fail.(*packong.LimitError); ok {
+		werr(w, err.from(fail), limitStatus(le), le.Error())
+		return
+	}
 	if fail != nil {
 		werr(w, err.from(fail), 500, "packing error")
 		return
@@ -176,13 +312,28 @@ func fitboxes(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	var labels map[string]string
+	if resp.LabelCols > 0 && resp.LabelRows > 0 {
+		louts, fail := op.Labels(rep, packong.NewLabelSheet(resp.LabelCols, resp.LabelRows))
+		if fail != nil {
+			werr(w, err.from(fail), 422, "couldn't make labels")
+			return
+		}
+		labels, errs = writeSvg(louts)
+		if len(errs) > 0 {
+			werr(w, err.from(errs[0]), 500, "error preparing labels")
+			return
+		}
+	}
 	repJson := packong.ReportJSON((*rep))
 	out := struct {
-		Rep  packong.ReportJSON `json:"rep,omitempty"`
-		Svgs map[string]string  `json:"svgs,omitempty"`
+		Rep    packong.ReportJSON `json:"rep,omitempty"`
+		Svgs   map[string]string  `json:"svgs,omitempty"`
+		Labels map[string]string  `json:"labels,omitempty"`
 	}{
 		repJson,
 		svgs,
+		labels,
 	}
 	b, fail := json.Marshal(out)
 	if fail != nil {
@@ -197,6 +348,15 @@ func fitboxes(w http.ResponseWriter, r *http.Request) {
 	io.Copy(w, bytes.NewReader(b))
 }
 
+// limitStatus tells too much input from a job that can't be done within limits
+func limitStatus(le *packong.LimitError) int {
+	switch le.Limit {
+	case "qty", "pieces", "candidates":
+		return 413 // payload too large
+	}
+	return 422
+}
+
 func writeSvg(outs []packong.FitReader) (svgs map[string]string, errs []error) {
 	var (
 		b   []byte
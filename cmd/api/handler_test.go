@@ -2,10 +2,17 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+
+	"github.com/innermond/packong"
 )
 
 func Test_fitboxes(t *testing.T) {
@@ -32,6 +39,16 @@ func Test_fitboxes(t *testing.T) {
 			{`{"width":500,"height":500,"dimensions":["501x"]}`, 422},
 			{`{"width":"50x","height":"x00"}`, 422},
 			{`{}`, 422},
+			{`{"width":500,"height":500,"dimensions":["50x50"],"currency":"xyz"}`, 422},
+			{`{"width":500,"height":500,"dimensions":["50x50"],"unit":"yd"}`, 422},
+			{`{"width":500,"height":500,"dimensions":["50x50x51"]}`, 413},
+			{`{"width":500,"height":500,"shapes":["path=\"M0 0 X\""]}`, 422},
+			{`{"width":330,"height":480,"dimensions":["500x50"],"how_many":1}`, 422},
+			{`{"width":330,"height":480,"dimensions":["90x50"],"how_many":-1}`, 422},
+			{`{"width":500,"height":500,"dimensions":["50x50"],"target_margin":100}`, 422},
+			{`{"width":500,"height":500,"dimensions":["50x50"],"vat":-19}`, 422},
+			{`{"width":500,"height":500,"dimensions":["50x50"],"font_min":5,"font_max":2}`, 422},
+			{`{"width":500,"height":500,"dimensions":["50x50"],"font_min":5}`, 422},
 		}
 		var buf *bytes.Buffer
 
@@ -75,6 +92,10 @@ func Test_fitboxes(t *testing.T) {
 			{`{"width":1540,"height":50000,"dimensions":["500x1500x10"]}`, 200},
 			{`{"width":1270,"height":50000,"dimensions":["500x1200x10","780x650x3","890x1300"]}`, 200},
 			{`{"width":500,"height":500,"dimensions":["501x501"]}`, 200},
+			{`{"width":48,"height":96,"unit":"in","dimensions":["24 1/2x36x3","11-3/4x8.5"]}`, 200},
+			{`{"width":1270,"height":50000,"dimensions":["500x1200 qty=2 rotate=no label=\"door\" priority=1","780x650x3"]}`, 200},
+			{`{"width":1000,"height":5000,"shapes":["poly=\"0,0 200,0 200,30 30,30 30,150 0,150\" qty=4"],"step":90}`, 200},
+			{`{"width":330,"height":480,"dimensions":["90x50"],"how_many":1}`, 200},
 		}
 		var buf *bytes.Buffer
 
@@ -102,6 +123,105 @@ func Test_fitboxes(t *testing.T) {
 		}
 	})
 
+	t.Run("limit hit while packing", func(t *testing.T) {
+		defer func(l packong.Limits) { limits = l }(limits)
+		limits.MaxCandidates = 1
+
+		data := `{"width":500,"height":500,"dimensions":["50x50"]}`
+		resp := post(t, ts.URL+"/", bytes.NewBufferString(data))
+		defer resp.Body.Close()
+
+		if resp.StatusCode != 413 {
+			t.Errorf("got status %d, expected 413", resp.StatusCode)
+		}
+	})
+
+	t.Run("every bad dimension listed", func(t *testing.T) {
+		data := `{"width":500,"height":500,"dimensions":["501x","50x50","0x50xmany"]}`
+		resp := post(t, ts.URL+"/", bytes.NewBufferString(data))
+		defer resp.Body.Close()
+
+		if resp.StatusCode != 422 {
+			t.Fatalf("got status %d, expected 422", resp.StatusCode)
+		}
+		var list []struct {
+			Entry int
+			Field string
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+		want := "0 height, 2 qty, 2 width"
+		got := []string{}
+		for _, inv := range list {
+			got = append(got, fmt.Sprintf("%d %s", inv.Entry, inv.Field))
+		}
+		if strings.Join(got, ", ") != want {
+			t.Errorf("got %v, expected %s", got, want)
+		}
+	})
+
+	t.Run("csv cut list", func(t *testing.T) {
+
+		tt := []struct {
+			test
+			columns string
+		}{
+			{test{"width,height,qty,label\n500,1200,2,door\n780,650,3,\n", 200}, ""},
+			{test{"width,height,qty\n500,x,2\n780,650,0\n", 422}, ""},
+			{test{"length,qty\n500,2\n", 422}, ""},
+			{test{"L,W,n\n500,1200,2\n", 200}, "width=L,height=W,qty=n"},
+			{test{"L,W,n\n500,1200,2\n", 422}, "width"},
+		}
+
+		for _, tc := range tt {
+			q := url.Values{"width": {"1270"}, "height": {"50000"}, "unit": {"mm"}, "columns": {tc.columns}}
+			resp, err := http.Post(ts.URL+"/?"+q.Encode(), "text/csv", bytes.NewBufferString(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Errorf("got status %d, expected %d", resp.StatusCode, tc.status)
+			}
+		}
+	})
+
+	t.Run("artwork upload", func(t *testing.T) {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		mw.WriteField("job", `{"width":1000,"height":5000,"unit":"cm","art_scale":2}`)
+		fw, err := mw.CreateFormFile("artwork", "flyer.svg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(fw, `<svg width="210mm" height="297mm"></svg>`)
+		mw.Close()
+
+		resp, err := http.Post(ts.URL, mw.FormDataContentType(), &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			t.Fatalf("got status %d, expected 200", resp.StatusCode)
+		}
+		var out struct {
+			Rep struct {
+				Layout []struct {
+					Piece struct {
+						Label string
+						W, H  float64
+					}
+				}
+			}
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+		if l := out.Rep.Layout; len(l) != 1 || l[0].Piece.Label != "flyer" || l[0].Piece.W != 42 || l[0].Piece.H != 59.4 {
+			t.Errorf("got %+v, expected a 42x59.4 flyer", l)
+		}
+	})
 }
 
 func post(t *testing.T, url string, buf *bytes.Buffer) *http.Response {
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/innermond/packong"
+)
+
+// maximum bytes of a multipart request kept in memory
+const maxMemory = 10 << 20
+
+// decodeInput fills resp from request's body; a cut list, either posted as text/csv
+// or as "cutlist" file of a multipart form, gives dimensions added to resp's,
+// its headers mapped by a "columns" field such as "width=L,height=W,qty=Q";
+// so do "artwork" files of a multipart form, kept into art by their names
+func decodeInput(r *http.Request, resp *ResponseData, art map[string][]byte) error {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch ct {
+	case "text/csv":
+		// parameters come by query string
+		if err := decodeQuery(r.URL.Query(), resp); err != nil {
+			return err
+		}
+		cols, err := packong.ParseColumns(r.URL.Query().Get("columns"))
+		if err != nil {
+			return err
+		}
+		specs, err := packong.ReadCSV(r.Body, cols)
+		if err != nil {
+			return err
+		}
+		resp.Dimensions = append(resp.Dimensions, packong.Dimensions(specs)...)
+		return nil
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			return err
+		}
+		if job := r.FormValue("job"); job != "" {
+			if err := json.Unmarshal([]byte(job), resp); err != nil {
+				return err
+			}
+		}
+		f, fh, err := r.FormFile("cutlist")
+		if err == nil {
+			defer f.Close()
+			cols, err := packong.ParseColumns(r.FormValue("columns"))
+			if err != nil {
+				return err
+			}
+			specs, err := packong.ReadCutList(fh.Filename, f, cols)
+			if err != nil {
+				return err
+			}
+			resp.Dimensions = append(resp.Dimensions, packong.Dimensions(specs)...)
+		} else if err != http.ErrMissingFile {
+			return err
+		}
+		return decodeArtwork(r.MultipartForm.File["artwork"], resp, art)
+	}
+	return json.NewDecoder(r.Body).Decode(resp)
+}
+
+// decodeArtwork adds a piece as large as every artwork file prints
+func decodeArtwork(files []*multipart.FileHeader, resp *ResponseData, art map[string][]byte) error {
+	if len(files) == 0 {
+		return nil
+	}
+	name := resp.Unit
+	if name == "" {
+		name = "mm"
+	}
+	u, err := packong.LookupUnit(name)
+	if err != nil {
+		return err
+	}
+	specs := []packong.PieceSpec{}
+	for _, fh := range files {
+		f, err := fh.Open()
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		a, err := packong.ReadArtwork(fh.Filename, bytes.NewReader(b))
+		if err != nil {
+			return err
+		}
+		art[fh.Filename] = b
+		specs = append(specs, a.Spec(u, resp.ArtScale))
+	}
+	resp.Dimensions = append(resp.Dimensions, packong.Dimensions(specs)...)
+	return nil
+}
+
+// decodeQuery fills resp from query parameters named as its json fields
+func decodeQuery(q url.Values, resp *ResponseData) error {
+	m := map[string]interface{}{}
+	t := reflect.TypeOf(*resp)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		vv, ok := q[name]
+		if !ok || len(vv) == 0 {
+			continue
+		}
+		v := vv[0]
+		switch f.Type.Kind() {
+		case reflect.String:
+			m[name] = v
+		case reflect.Slice:
+			m[name] = vv
+		case reflect.Bool:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			m[name] = b
+		default:
+			m[name] = json.Number(v)
+		}
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, resp)
+}
+
+// wlist answers a list of problems client can point at one by one
+func wlist(w http.ResponseWriter, list interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(422)
+	json.NewEncoder(w).Encode(list)
+}
+
+// uploaded opens artwork among files uploaded along request only
+func uploaded(art map[string][]byte) packong.ArtOpener {
+	return func(name string) (io.ReadCloser, error) {
+		b, ok := art[name]
+		if !ok {
+			return nil, fmt.Errorf("artwork %q was not uploaded", name)
+		}
+		return ioutil.NopCloser(bytes.NewReader(b)), nil
+	}
+}
@@ -49,8 +49,7 @@ func main() {
 	flag.IntVar(&timePeak, "t", timePeakEnv, "set a time limiter in milliseconds; no more than a request in that time '-t 200'")
 	_, debugEnv := os.LookupEnv("PACKONG_DEBUG")
 	flag.BoolVar(&debug, "debug", debugEnv, "debug mode '-debug'")
-	flag.Parse()
-
+	// parses all flags, those defined above included
 	param()
 
 	var (
@@ -1,11 +1,60 @@
 package main
 
-import "flag"
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/innermond/packong"
+)
 
 // it shows every step
 var verbose bool
 
+// price list used by all requests, if any
+var priceList *packong.PriceList
+
+// exchange rates used by all requests, if any
+var rates packong.Rates
+
+// limits of every request's job
+var limits = packong.DefaultLimits
+
 func param() {
+	var pricelist, ratesfile, limitstext string
+
 	flag.BoolVar(&verbose, "verbose", false, "tell me more about you")
+	flag.StringVar(&pricelist, "pricelist", env("PACKONG_PRICELIST", ""), "json file with tiered rates, minimum charges and discounts")
+	flag.StringVar(&ratesfile, "rates", env("PACKONG_RATES", ""), "json file with exchange rates by currency name")
+	flag.StringVar(&limitstext, "limits", env("PACKONG_LIMITS", ""), "job limits as \"qty=50,pieces=500,sheets=20,candidates=100,width=3200,height=100000\"")
 	flag.Parse()
+
+	var err error
+	if limits, err = packong.ParseLimits(limitstext); err != nil {
+		log.Fatal(err)
+	}
+
+	if pricelist != "" {
+		f, err := os.Open(pricelist)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		priceList, err = packong.LoadPriceList(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if ratesfile != "" {
+		f, err := os.Open(ratesfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		rates, err = packong.LoadRates(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 }
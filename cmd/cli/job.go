@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/innermond/packong"
+)
+
+// loadJob reads a job file into flag variables; parsing command line again
+// afterwards lets flags override the file
+func loadJob(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// fields missing from file keep flags' values
+	job := toJob()
+	if err := packong.DecodeJob(name, f, &job); err != nil {
+		return fmt.Errorf("job %s: %v", name, err)
+	}
+	fromJob(job)
+	return flag.CommandLine.Parse(os.Args[1:])
+}
+
+func toJob() packong.Job {
+	j := packong.Job{
+		Outname:       outname,
+		Unit:          unit,
+		Tight:         tight,
+		Plain:         plain,
+		ShowDim:       showDim,
+		FontMin:       fontmin,
+		FontMax:       fontmax,
+		Title:         title,
+		Material:      material,
+		Legend:        legend,
+		Cutwidth:      cutwidth,
+		Topleftmargin: topleftmargin,
+		Mu:            mu,
+		Ml:            ml,
+		Pp:            pp,
+		Pd:            pd,
+		Group:         group,
+		Pb:            pb,
+		Ph:            ph,
+		TargetMargin:  target,
+		ManualPrice:   manual,
+		Currency:      rn,
+		Vat:           vat,
+		RoundLine:     roundline,
+		RoundTotal:    roundtotal,
+		Greedy:        greedy,
+		Vendorsellint: vendorsellint,
+		Tile:          tile,
+		Overlap:       overlap,
+		Bleed:         bleed,
+		Gutter:        gutter,
+		Shapes:        shapeEntries,
+		Step:          step,
+		Spacing:       spacing,
+		ArtScale:      artscale,
+		Impose:        impose,
+		HowMany:       howmany,
+	}
+	j.Margins, _ = packong.ParseMargins(margins)
+	j.Marks, _ = packong.ParseMarks(marks)
+	j.RoundStep, _ = packong.ParseMoney(roundstep)
+	if wh := strings.Split(bigbox, "x"); len(wh) == 2 {
+		j.Width, _ = strconv.ParseFloat(wh[0], 64)
+		j.Height, _ = strconv.ParseFloat(wh[1], 64)
+	}
+	return j
+}
+
+func fromJob(j packong.Job) {
+	dimensions = j.Dimensions
+	outname = j.Outname
+	unit = j.Unit
+	tight = j.Tight
+	plain = j.Plain
+	showDim = j.ShowDim
+	fontmin, fontmax = j.FontMin, j.FontMax
+	title, material, legend = j.Title, j.Material, j.Legend
+	cutwidth, topleftmargin = j.Cutwidth, j.Topleftmargin
+	mu, ml, pp, pd = j.Mu, j.Ml, j.Pp, j.Pd
+	group, pb, ph = j.Group, j.Pb, j.Ph
+	target, manual = j.TargetMargin, j.ManualPrice
+	rn, vat = j.Currency, j.Vat
+	roundline, roundtotal, roundstep = j.RoundLine, j.RoundTotal, j.RoundStep.String()
+	greedy, vendorsellint = j.Greedy, j.Vendorsellint
+	tile, overlap = j.Tile, j.Overlap
+	bleed, gutter = j.Bleed, j.Gutter
+	shapeEntries, step, spacing = j.Shapes, j.Step, j.Spacing
+	artscale, impose = j.ArtScale, j.Impose
+	howmany = j.HowMany
+	if j.Margins != (packong.Margins{}) {
+		margins = j.Margins.String()
+	}
+	marks = j.Marks.String()
+	bigbox = fmt.Sprintf("%vx%v", j.Width, j.Height)
+	if j.LabelCols > 0 && j.LabelRows > 0 {
+		labels = fmt.Sprintf("%dx%d", j.LabelCols, j.LabelRows)
+	}
+}
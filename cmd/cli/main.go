@@ -11,6 +11,8 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -34,7 +36,9 @@ var (
 
 	cutwidth, topleftmargin float64
 
-	mu, ml, pp, pd, ph float64
+	gutter float64
+
+	mu, ml, pp, pd, ph, pb float64
 
 	rx float64
 	rn string
@@ -42,6 +46,48 @@ var (
 	showOffer, spor bool
 
 	selltext string
+
+	title, material string
+	legend          bool
+
+	fontmin, fontmax float64
+
+	labels    string
+	labelspdf bool
+
+	pricelist, group string
+
+	target, manual float64
+
+	rates string
+	vat   float64
+
+	roundline, roundtotal string
+	roundstep             string
+
+	cutlist, columns string
+
+	job string
+
+	limits string
+
+	tile    bool
+	overlap float64
+
+	margins string
+	bleed   float64
+
+	shapes        string
+	shapeEntries  []string
+	step, spacing float64
+
+	art      string
+	artscale float64
+	impose   string
+
+	marks string
+
+	howmany int
 )
 
 func param() error {
@@ -59,24 +105,64 @@ func param() error {
 	flag.BoolVar(&vendorsellint, "vendorsellint", true, "vendors sells an integer number of sheet length")
 	flag.BoolVar(&deep, "deep", false, "calculate all boxes permutations")
 	flag.BoolVar(&showOffer, "offer", false, "show a text representing offer")
-	flag.BoolVar(&spor, "spor", false, "spor")
+	flag.BoolVar(&spor, "spor", false, "show what price brings over costs")
+	flag.Float64Var(&target, "target", 0.0, "price follows from costs keeping this margin percent")
+	flag.Float64Var(&manual, "manual", 0.0, "price set by hand; margin follows from costs")
 	flag.StringVar(&fo, "fo", "", "template offer filename")
+	flag.StringVar(&title, "title", "", "job name written into a title block under every sheet")
+	flag.StringVar(&material, "material", "", "material written into title block")
+	flag.BoolVar(&legend, "legend", false, "add a legend explaining colours of boxes")
 
 	flag.Float64Var(&mu, "mu", 15.0, "used material price per 1 square meter")
 	flag.Float64Var(&ml, "ml", 5.0, "lost material price per 1 square meter")
 	flag.Float64Var(&pp, "pp", 0.25, "perimeter price per 1 linear meter; used for evaluating cuts price")
 	flag.Float64Var(&pd, "pd", 15, "travel price to location")
 	flag.Float64Var(&ph, "ph", 3.5, "man power price")
-	flag.Float64Var(&rx, "rx", 1.0, "rate exchange")
+	flag.StringVar(&pricelist, "pricelist", "", "json file with tiered rates, minimum charges and discounts")
+	flag.StringVar(&group, "group", "", "customer group looked up for discounts in price list")
+	flag.Float64Var(&pb, "pb", 0.0, "banding price per 1 linear meter of edge tape")
+	flag.Float64Var(&rx, "rx", 1.0, "rate exchange; used when there is no rates file")
 	flag.StringVar(&rn, "rn", "eur", "currency name - it will be used in reports")
+	flag.StringVar(&rates, "rates", "", "json file with exchange rates by currency name")
+	flag.Float64Var(&vat, "vat", 0.0, "value added tax percent")
+	flag.StringVar(&roundline, "roundline", "halfup", "rounding of every price line: halfup, halfeven, down, up")
+	flag.StringVar(&roundtotal, "roundtotal", "halfup", "rounding of totals: halfup, halfeven, down, up")
+	flag.StringVar(&roundstep, "roundstep", "0.01", "totals are rounded to a multiple of it")
 	flag.Float64Var(&cutwidth, "cutwidth", 0.0, "the with of material that is lost due to a cut")
-	flag.Float64Var(&topleftmargin, "margin", 0.0, "offset from top left margin")
+	flag.Float64Var(&gutter, "gutter", 0.0, "gap kept between pieces, apart from cut width")
+	flag.Float64Var(&topleftmargin, "margin", 0.0, "offset from top left margin; top one counts in used length")
+	flag.StringVar(&margins, "margins", "", "sheet margins as \"top,right,bottom,left\"; fewer values repeat like css does; it replaces -margin; top and bottom ones count in used length")
+	flag.Float64Var(&bleed, "bleed", 0.0, "how much pieces extend past their trim edges")
+	flag.StringVar(&labels, "labels", "", "print a label for every piece on label sheets having \"colsxrows\" labels")
+	flag.BoolVar(&labelspdf, "labelspdf", false, "labels are saved as pdf instead of svg")
+	flag.Float64Var(&fontmin, "fontmin", 0.0, "smallest font size of dimensions in units; 0 means default")
+	flag.Float64Var(&fontmax, "fontmax", 0.0, "biggest font size of dimensions in units; 0 means default")
+	flag.StringVar(&cutlist, "i", "", "csv or xlsx cut list; its pieces are added to dimensions")
+	flag.StringVar(&columns, "columns", "", "headers of cut list columns as \"width=W,height=H,qty=Q,rotate=R,label=L\"")
+
+	flag.StringVar(&limits, "limits", "", "job limits as \"qty=50,pieces=500,sheets=20,candidates=100,width=3200,height=100000\"")
+	flag.BoolVar(&tile, "tile", false, "split pieces too large for sheet into overlapping panels")
+	flag.Float64Var(&overlap, "overlap", 0.0, "overlap of neighbour panels when tiling")
+	flag.StringVar(&shapes, "shapes", "", "file of shape entries, one a line, nested by their outlines instead of packing dimensions")
+	flag.Float64Var(&step, "step", 0.0, "rotation step in degrees of nested shapes; 0 keeps them as drawn")
+	flag.Float64Var(&spacing, "spacing", 0.0, "gap kept between outlines of nested shapes")
+	flag.StringVar(&art, "art", "", "png, jpeg or svg artwork, or a pattern like *.png; every file is a piece as large as it prints")
+	flag.Float64Var(&artscale, "artscale", 1.0, "artwork prints this many times its size")
+	flag.StringVar(&impose, "impose", "", "place artwork of pieces into sheets: embed or link")
+	flag.StringVar(&marks, "marks", "", "crop and registration marks as \"crop=5,offset=2,reg=circle,size=5,inset=10,every=500\"")
+	flag.IntVar(&howmany, "howmany", 0, "fill this many sheets with as many pieces as fit, quantities being ratios")
+	flag.StringVar(&job, "job", "", "json or yaml job file; flags given on command line override it")
 
 	flag.Parse()
+	if job != "" {
+		if err = loadJob(job); err != nil {
+			return err
+		}
+	}
 
 	selltext = `Oferta pentru suprafetele
 {{.Dimensions}} in {{.Unit}}
-este de {{printf "%.2f" (mul .Price ` + fmt.Sprintf("%.2f", rx) + `)}} ` + rn + ` + TVA.
+este de {{.Net}} {{.Currency}} + TVA {{.VAT}} {{.Currency}} = {{.Gross}} {{.Currency}}.
 Include productie, montaj, deplasare.`
 
 	wh = strings.Split(bigbox, "x")
@@ -101,10 +187,52 @@ Include productie, montaj, deplasare.`
 		}
 		selltext = string(bb)
 	}
-	dimensions = flag.Args()
-	if len(dimensions) == 0 {
+	args := flag.Args()
+	if art != "" {
+		// a shell expands -art *.png into files following flag
+		names, err := filepath.Glob(art)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no artwork matches %s", art)
+		}
+		for len(args) > 0 && packong.IsArtwork(args[0]) {
+			if _, err := os.Stat(args[0]); err != nil {
+				break
+			}
+			names, args = append(names, args[0]), args[1:]
+		}
+		dd, err := artwork(names)
+		if err != nil {
+			return err
+		}
+		dimensions = append(dimensions, dd...)
+	}
+	dimensions = append(dimensions, args...)
+	if cutlist != "" {
+		cols, err := packong.ParseColumns(columns)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(cutlist)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		specs, err := packong.ReadCutList(cutlist, f, cols)
+		if err != nil {
+			return err
+		}
+		dimensions = append(dimensions, packong.Dimensions(specs)...)
+	}
+	if len(dimensions) == 0 && shapes == "" && len(shapeEntries) == 0 {
 		return errors.New("dimensions required")
 	}
+	// zero sizes keep defaults; others must make a range
+	if (fontmin != 0 || fontmax != 0) && (fontmin <= 0 || fontmax < fontmin) {
+		return fmt.Errorf("fontmin %v and fontmax %v need 0 < fontmin <= fontmax", fontmin, fontmax)
+	}
 
 	flag.Visit(func(f *flag.Flag) {
 		switch f.Name {
@@ -148,43 +276,99 @@ func main() {
 		Outname(outname).
 		Appearance(plain, showDim).
 		Cutwidth(cutwidth).
+		Gutter(gutter).
 		Price(mu, ml, pp, pd).
+		BandingPrice(pb).
+		Labour(ph).
 		Greedy(greedy).
-		VendorSellInt(vendorsellint)
-	// if the cut can eat half of its width along cutline
-	// we compensate expanding boxes with an entire cut width
-	boxes, err := op.BoxesFromString()
+		VendorSellInt(vendorsellint).
+		Material(material).
+		Legend(legend)
+	lim, err := packong.ParseLimits(limits)
 	if err != nil {
 		log.Fatal(err)
 	}
-	pp := [][]*pak.Box{boxes}
-	if deep {
-		pp = packong.Permutations(boxes)
-		// take approval from user
-		fmt.Printf("%d combinations. Can take a much much longer time. Continue?\n", len(pp))
-		var (
-			yn string
-			r  *bufio.Reader = bufio.NewReader(os.Stdin)
-		)
-	approve:
-		for {
-			fmt.Println("Enter y to continue or a n to abort")
-			yn, err = r.ReadString('\n')
-			yn = strings.TrimRight(yn, "\n")
-			if err != nil {
-				continue
-			}
-
-			switch yn {
-			case "y":
-				break approve
-			case "n":
-				fmt.Println("user aborted packing operation")
-				os.Exit(0)
-			}
+	op.Limits(lim)
+	op.Topleft(topleftmargin).Bleed(bleed)
+	if margins != "" {
+		m, err := packong.ParseMargins(margins)
+		if err != nil {
+			log.Fatal(err)
 		}
+		op.Margins(m)
+	}
+	if tile {
+		op.Tiling(overlap)
+	}
+	if marks != "" {
+		m, err := packong.ParseMarks(marks)
+		if err != nil {
+			log.Fatal(err)
+		}
+		op.Marks(m)
+	}
+	switch impose {
+	case "":
+	case "embed", "link":
+		op.Imposition(impose == "embed", nil)
+	default:
+		log.Fatalf("impose %q is neither embed nor link", impose)
+	}
+	if title != "" {
+		op.Title(title, material)
+	}
+	if rates != "" {
+		f, err := os.Open(rates)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rr, err := packong.LoadRates(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+		op.Currency(rn, rr)
+	} else {
+		op.Currency(rn, packong.Rates{rn: rx})
+	}
+	if vat < 0 {
+		log.Fatalf("vat %v%% is below zero", vat)
+	}
+	op.VAT(vat)
+	rp, err := rounding(roundline, roundtotal, roundstep)
+	if err != nil {
+		log.Fatal(err)
+	}
+	op.Rounding(rp)
+	if target > 0 {
+		op.TargetMargin(target)
+	}
+	if manual > 0 {
+		op.ManualPrice(manual)
+	}
+	if pricelist != "" {
+		f, err := os.Open(pricelist)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pl, err := packong.LoadPriceList(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+		op.PriceList(pl, group)
+	}
+	if fontmin > 0 {
+		op.DimFont(fontmin, fontmax)
+	}
+	switch {
+	case shapes != "" || len(shapeEntries) > 0:
+		rep, outs, err = nest(op)
+	case howmany > 0:
+		rep, outs, err = op.HowMany(howmany)
+	default:
+		rep, outs, err = fit(op)
 	}
-	rep, outs, err = op.Fit(pp, deep)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -193,6 +377,9 @@ func main() {
 	if rep.UnfitLen > 0 {
 		fmt.Fprintf(tw, "%s\t%d\n", "UnfitLen", rep.UnfitLen)
 		fmt.Fprintf(tw, "%s\t%s\n", "UnfitCode", rep.UnfitCode)
+		for _, u := range rep.Unfit {
+			fmt.Fprintf(tw, "Unfit #%d %s\t%s\t%s\n", u.Piece.ID, u.Piece.Label, u.Note, u.Suggestion)
+		}
 	}
 
 	pieces := strings.Join(dimensions, " ")
@@ -206,16 +393,41 @@ func main() {
 	fmt.Fprintf(tw, "%s\t%.2f\n", "VendoredWidth", rep.VendoredWidth)
 	fmt.Fprintf(tw, "%s\t%.2f\n", "ProcentArea", rep.ProcentArea)
 	fmt.Fprintf(tw, "%s\t%.2f\n", "NumSheetUsed", rep.NumSheetUsed)
-	fmt.Fprintf(tw, "%s\t%.2f\n", "Price", rx*rep.Price)
-	fmt.Fprintf(tw, "%s\t%.2f\n", "Materials cost", rx*rep.VendoredArea*ml)
-	fmt.Fprintf(tw, "%s\t%.2f\n", "Man cost", rx*rep.BoxesArea*ph)
-	fmt.Fprintf(tw, "%s\t%.2f\n", "Travel cost", rx*pd)
+	if c := rep.Capacity; c != nil {
+		for i, d := range dimensions {
+			fmt.Fprintf(tw, "%s\t%d\n", "Fit "+d, c.Qty[i])
+		}
+		fmt.Fprintf(tw, "%s\t%d on %d sheets\n", "Fit", c.Total, c.Sheets)
+	}
+	tapes := []string{}
+	for tape := range rep.Banding {
+		tapes = append(tapes, tape)
+	}
+	sort.Strings(tapes)
+	for _, tape := range tapes {
+		fmt.Fprintf(tw, "%s\t%.2f\n", "Banding "+tape, rep.Banding[tape])
+	}
+	for _, l := range rep.PriceLines {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", "Price "+l.Name, l.Amount, l.Note)
+	}
+	fmt.Fprintf(tw, "%s\t%s %s\n", "Price", rep.Price, rep.Currency)
+	fmt.Fprintf(tw, "%s\t%s %s\n", fmt.Sprintf("VAT %.2f%%", rep.VATRate), rep.VAT, rep.Currency)
+	fmt.Fprintf(tw, "%s\t%s %s\n", "Gross", rep.Gross, rep.Currency)
+	for _, l := range rep.Costs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", "Cost "+l.Name, l.Amount, l.Note)
+	}
+	fmt.Fprintf(tw, "%s\t%s\n", "Cost", rep.Cost)
 	if spor {
-		fmt.Fprintf(tw, "%s\t%.2f\n", "Spor", rx*(rep.Price-(rep.VendoredArea*ml+rep.BoxesArea*ph+pd)))
+		fmt.Fprintf(tw, "%s\t%s\n", "Spor", rep.Price.Sub(rep.Cost))
+		fmt.Fprintf(tw, "%s\t%.2f%%\n", "Margin", rep.Margin)
+	}
+	for _, warn := range rep.Warnings {
+		fmt.Fprintf(tw, "%s\t%s\n", "Warning", warn)
 	}
 	if showOffer {
-		mul := func(a float64, b float64) float64 {
-			return a * b
+		// offer templates written for float prices keep using mul
+		mul := func(a packong.Money, b float64) float64 {
+			return a.Float() * b
 		}
 		tpl, err := template.New("offer").Funcs(template.FuncMap{"mul": mul}).Parse(selltext)
 		if err != nil {
@@ -241,6 +453,38 @@ func main() {
 			log.Println(errs)
 		}
 	}
+	if labels != "" {
+		cr := strings.Split(labels, "x")
+		if len(cr) != 2 {
+			log.Fatal("labels grid must be \"colsxrows\"")
+		}
+		cols, err := strconv.Atoi(cr[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		rows, err := strconv.Atoi(cr[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		ls := packong.NewLabelSheet(cols, rows)
+		var louts []packong.FitReader
+		if labelspdf {
+			out, err := op.LabelsPDF(rep, ls)
+			if err != nil {
+				log.Fatal(err)
+			}
+			louts = append(louts, out)
+		} else {
+			louts, err = op.Labels(rep, ls)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		errs := writeFiles(louts)
+		if len(errs) > 0 {
+			log.Println(errs)
+		}
+	}
 }
 
 func writeFiles(outs []packong.FitReader) (errs []error) {
@@ -261,3 +505,119 @@ func writeFiles(outs []packong.FitReader) (errs []error) {
 	}
 	return
 }
+
+func rounding(line, total, step string) (rp packong.RoundingPolicy, err error) {
+	rp.Line, err = packong.ParseRounding(line)
+	if err != nil {
+		return
+	}
+	rp.Total, err = packong.ParseRounding(total)
+	if err != nil {
+		return
+	}
+	rp.Step, err = packong.ParseMoney(step)
+	return
+}
+
+// fit packs pieces by their boxes
+func fit(op *packong.Op) (*packong.Report, []packong.FitReader, error) {
+	// if the cut can eat half of its width along cutline
+	// we compensate expanding boxes with an entire cut width
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		return nil, nil, err
+	}
+	pp := [][]*pak.Box{boxes}
+	if deep {
+		// limits are checked before permutations fill memory
+		if err := op.CheckCandidates(packong.NumPermutations(len(boxes))); err != nil {
+			return nil, nil, err
+		}
+		pp = packong.Permutations(boxes)
+		// take approval from user
+		fmt.Printf("%d combinations. Can take a much much longer time. Continue?\n", len(pp))
+		var (
+			yn string
+			r  *bufio.Reader = bufio.NewReader(os.Stdin)
+		)
+	approve:
+		for {
+			fmt.Println("Enter y to continue or a n to abort")
+			yn, err = r.ReadString('\n')
+			yn = strings.TrimRight(yn, "\n")
+			if err != nil {
+				continue
+			}
+
+			switch yn {
+			case "y":
+				break approve
+			case "n":
+				fmt.Println("user aborted packing operation")
+				os.Exit(0)
+			}
+		}
+	}
+	return op.Fit(pp, deep)
+}
+
+// nest packs shapes of job and shapes file by their outlines
+func nest(op *packong.Op) (*packong.Report, []packong.FitReader, error) {
+	entries := shapeEntries
+	if shapes != "" {
+		f, err := os.Open(shapes)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+
+		sc := bufio.NewScanner(f)
+		// outlines may be long
+		sc.Buffer(nil, 1<<24)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			entries = append(entries, line)
+		}
+		if err := sc.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ss := []packong.Shape{}
+	for _, e := range entries {
+		sh, err := packong.ParseShape(e)
+		if err != nil {
+			return nil, nil, err
+		}
+		ss = append(ss, sh)
+	}
+	return op.Nest(ss, packong.Nesting{Step: step, Spacing: spacing})
+}
+
+// artwork gives dimensions of pieces as large as artwork files print
+func artwork(names []string) ([]string, error) {
+	u, err := packong.LookupUnit(unit)
+	if err != nil {
+		return nil, err
+	}
+	specs := []packong.PieceSpec{}
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		a, err := packong.ReadArtwork(name, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		if a.Guessed {
+			fmt.Fprintf(os.Stderr, "%s tells no resolution or size; its size is guessed\n", name)
+		}
+		specs = append(specs, a.Spec(u, artscale))
+	}
+	return packong.Dimensions(specs), nil
+}
@@ -0,0 +1,72 @@
+package packong
+
+import "fmt"
+
+// CostPlus prices a job from its true costs so that a target margin is kept
+type CostPlus struct {
+	// Ml material bought per square meter, Ph labour per square meter of used material,
+	// Pp cutting and Pb edge banding per linear meter, Pd travel
+	Ml, Ph, Pp, Pd, Pb float64
+	// Margin is the percent of selling price kept as profit
+	Margin float64
+	// Fixed is a price entered by hand; when positive it wins over Margin
+	Fixed float64
+}
+
+// Costs itemises what a job costs
+func (cp CostPlus) Costs(rep *Report) Breakdown {
+	return Breakdown{
+		{Name: LineMaterial, Amount: rep.VendoredArea * cp.Ml, Note: "material bought"},
+		{Name: LineCutting, Amount: rep.BoxesPerim * cp.Pp},
+		{Name: LineBanding, Amount: rep.BandingLength() * cp.Pb},
+		{Name: LineLabour, Amount: rep.BoxesArea * cp.Ph},
+		{Name: LineTravel, Amount: cp.Pd},
+	}
+}
+
+// Price implements PriceModel; costs are followed by a markup line
+// that takes the price up to target margin or to the fixed price
+func (cp CostPlus) Price(rep *Report) Breakdown {
+	b := cp.Costs(rep)
+	cost := b.Total()
+
+	if cp.Fixed > 0 {
+		return append(b, PriceLine{Name: LineMarkup, Amount: cp.Fixed - cost, Note: "price set by hand"})
+	}
+
+	price := cost / (1 - cp.Margin/100)
+	return append(b, PriceLine{Name: LineMarkup, Amount: price - cost, Note: fmt.Sprintf("margin %.2f%%", cp.Margin)})
+}
+
+// Check tells margin leaves a price; a margin of 100% or more has none
+func (cp CostPlus) Check() error {
+	if cp.Fixed <= 0 && cp.Margin >= 100 {
+		return fmt.Errorf("margin %v%% leaves no price; it must be below 100%%", cp.Margin)
+	}
+	return nil
+}
+
+// Labour sets cost of labour per square meter of used material
+func (op *Op) Labour(ph float64) *Op {
+	op.ph = ph
+	return op
+}
+
+// TargetMargin makes price follow from costs so that margin percent is kept;
+// a margin of 100% or more fails pricing
+func (op *Op) TargetMargin(margin float64) *Op {
+	op.costplus = true
+	op.margin = margin
+	return op
+}
+
+// ManualPrice sets price by hand; report tells its margin and warns when it is below cost
+func (op *Op) ManualPrice(price float64) *Op {
+	op.costplus = true
+	op.manual = price
+	return op
+}
+
+func (op *Op) costs() CostPlus {
+	return CostPlus{Ml: op.ml, Ph: op.ph, Pp: op.pp, Pd: op.pd, Pb: op.pb, Margin: op.margin, Fixed: op.manual}
+}
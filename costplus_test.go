@@ -0,0 +1,68 @@
+package packong
+
+import (
+	"math"
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestCostPlus(t *testing.T) {
+	rep := &Report{VendoredArea: 4, BoxesArea: 2, BoxesPerim: 10}
+	cp := CostPlus{Ml: 10, Ph: 5, Pp: 1, Pd: 20, Margin: 20}
+	// 40 material, 10 cutting, 10 labour and 20 travel
+	if c := cp.Costs(rep).Total(); c != 80 {
+		t.Errorf("got costs %v, expected 80", c)
+	}
+	b := cp.Price(rep)
+	if p := b.Total(); math.Abs(p-100) > 1e-9 {
+		t.Errorf("got price %v, expected 100 keeping 20%% margin", p)
+	}
+	if l := b[len(b)-1]; l.Name != LineMarkup || math.Abs(l.Amount-20) > 1e-9 {
+		t.Errorf("got %+v, expected markup of 20", l)
+	}
+
+	cp.Fixed = 60
+	if p := cp.Price(rep).Total(); p != 60 {
+		t.Errorf("got price %v, expected 60 set by hand", p)
+	}
+
+	cp.Fixed, cp.Margin = 0, 100
+	if err := cp.Check(); err == nil {
+		t.Error("expected error for a margin of 100%")
+	}
+}
+
+func TestTargetMarginAndManualPrice(t *testing.T) {
+	fit := func(op *Op) (*Report, error) {
+		boxes, err := op.BoxesFromString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep, _, err := op.Fit([][]*pak.Box{boxes}, false)
+		return rep, err
+	}
+	op := func() *Op {
+		return NewOp(1000, 2000, []string{"500x500x2"}, "mm").Price(15, 5, 0.25, 15).Labour(3.5)
+	}
+
+	rep, err := fit(op().TargetMargin(25))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(rep.Margin-25) > 0.1 || len(rep.Warnings) != 0 {
+		t.Errorf("got margin %v warnings %v, expected 25%%", rep.Margin, rep.Warnings)
+	}
+
+	rep, err = fit(op().ManualPrice(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Price.String() != "1.00" || rep.Margin >= 0 || len(rep.Warnings) != 1 {
+		t.Errorf("got price %s margin %v warnings %v, expected 1.00 below cost", rep.Price, rep.Margin, rep.Warnings)
+	}
+
+	if _, err := fit(op().TargetMargin(100)); err == nil {
+		t.Error("expected error for a margin of 100%")
+	}
+}
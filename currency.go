@@ -0,0 +1,102 @@
+package packong
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Rates are exchange rates: how many units of a currency one unit of base currency buys
+type Rates map[string]float64
+
+// LoadRates reads a json rate table such as {"eur": 1, "ron": 4.75}
+func LoadRates(r io.Reader) (Rates, error) {
+	rates := Rates{}
+	if err := json.NewDecoder(r).Decode(&rates); err != nil {
+		return nil, err
+	}
+	for name, rate := range rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("rates: %q needs a positive rate; received %v", name, rate)
+		}
+	}
+	return rates, nil
+}
+
+// decimals of currencies whose minor unit is not a hundredth, by ISO 4217
+var decimals = map[string]int{
+	"bif": 0, "clp": 0, "djf": 0, "gnf": 0, "isk": 0, "jpy": 0, "kmf": 0, "krw": 0,
+	"pyg": 0, "rwf": 0, "ugx": 0, "vnd": 0, "vuv": 0, "xaf": 0, "xof": 0, "xpf": 0,
+	"bhd": 3, "iqd": 3, "jod": 3, "kwd": 3, "lyd": 3, "omr": 3, "tnd": 3,
+}
+
+// Decimals tells how many decimals amounts in currency have; most have two
+func Decimals(currency string) int {
+	if d, ok := decimals[strings.ToLower(currency)]; ok {
+		return d
+	}
+	return 2
+}
+
+// Currency makes report prices be in currency name, exchanged by rates;
+// prices given to op stay in base currency
+func (op *Op) Currency(name string, rates Rates) *Op {
+	op.currency = name
+	op.rates = rates
+	return op
+}
+
+// VAT sets value added tax percent; a negative one fails pricing
+func (op *Op) VAT(rate float64) *Op {
+	op.vat = rate
+	return op
+}
+
+// rate gives exchange rate of op's currency
+func (op *Op) rate() (float64, error) {
+	if op.rates == nil {
+		return 1.0, nil
+	}
+	r, ok := op.rates[op.currency]
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for currency %q", op.currency)
+	}
+	return r, nil
+}
+
+// bill fills report's prices and costs, exchanged into op's currency and rounded, then adds VAT
+func (op *Op) bill(rep *Report, lines, costs Breakdown) error {
+	if cp, ok := op.priceModel().(CostPlus); ok {
+		if err := cp.Check(); err != nil {
+			return err
+		}
+	}
+	rate, err := op.rate()
+	if err != nil {
+		return err
+	}
+	if op.vat < 0 {
+		return fmt.Errorf("vat %v%% is below zero", op.vat)
+	}
+
+	exp := Decimals(op.currency)
+	rep.PriceLines = op.rounding.Bill(lines, rate, exp)
+	rep.Price = rep.PriceLines.Total()
+	// costs are not quoted so they skip total's step
+	rep.Costs = RoundingPolicy{Line: op.rounding.Line, Total: op.rounding.Total}.Bill(costs, rate, exp)
+	rep.Cost = rep.Costs.Total()
+	if rep.Price.Sign() != 0 {
+		rep.Margin = rep.Price.Sub(rep.Cost).Float() * 100 / rep.Price.Float()
+	}
+	if rep.Price.Sub(rep.Cost).Sign() < 0 {
+		rep.Warnings = append(rep.Warnings, fmt.Sprintf("price %s %s is below cost %s %[2]s", rep.Price, op.currency, rep.Cost))
+	}
+
+	rep.Currency = op.currency
+	rep.VATRate = op.vat
+	rep.Net = rep.Price
+	rep.VAT = op.rounding.Total.Money(rep.Net.Float()*op.vat/100, exp)
+	rep.Gross = rep.Net.Add(rep.VAT)
+	return nil
+}
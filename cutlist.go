@@ -0,0 +1,341 @@
+package packong
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Columns maps cut list fields to the headers of their columns; headers match case insensitive
+type Columns struct {
+	Width, Height, Qty, Rotate, Label string
+}
+
+// DefaultColumns are headers looked up when none are given
+var DefaultColumns = Columns{Width: "width", Height: "height", Qty: "qty", Rotate: "rotate", Label: "label"}
+
+// ParseColumns reads a mapping such as "width=W,height=H,qty=Pieces";
+// fields left out keep their default header
+func ParseColumns(s string) (Columns, error) {
+	cols := DefaultColumns
+	if strings.TrimSpace(s) == "" {
+		return cols, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return cols, fmt.Errorf("column mapping %q needs field=header", kv)
+		}
+		field, header := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		switch field {
+		case "width":
+			cols.Width = header
+		case "height":
+			cols.Height = header
+		case "qty":
+			cols.Qty = header
+		case "rotate":
+			cols.Rotate = header
+		case "label":
+			cols.Label = header
+		default:
+			return cols, fmt.Errorf("unknown cut list field %q", field)
+		}
+	}
+	return cols, nil
+}
+
+// RowError is a problem found on a row of a cut list; rows count from 1, header included
+type RowError struct {
+	Row int    `json:"row"`
+	Err string `json:"error"`
+}
+
+// CutListError gathers problems of all rows of a cut list
+type CutListError []RowError
+
+func (e CutListError) Error() string {
+	ss := []string{}
+	for _, re := range e {
+		ss = append(ss, fmt.Sprintf("row %d: %s", re.Row, re.Err))
+	}
+	return "cut list: " + strings.Join(ss, "; ")
+}
+
+// ReadCutList reads a csv or xlsx cut list telling them apart by name's extension
+func ReadCutList(name string, r io.Reader, cols Columns) ([]PieceSpec, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".txt":
+		return ReadCSV(r, cols)
+	case ".xlsx":
+		return ReadXLSX(r, cols)
+	}
+	return nil, fmt.Errorf("cut list %q is neither csv nor xlsx", name)
+}
+
+// ReadCSV reads a cut list from csv; a header row names the columns
+func ReadCSV(r io.Reader, cols Columns) ([]PieceSpec, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	rows := []listRow{}
+	for n, cells := range records {
+		rows = append(rows, listRow{n + 1, cells})
+	}
+	return specsFromRows(rows, cols)
+}
+
+// ReadXLSX reads a cut list from the first worksheet of a xlsx workbook
+func ReadXLSX(r io.Reader, cols Columns) ([]PieceSpec, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := xlsxRows(b)
+	if err != nil {
+		return nil, err
+	}
+	return specsFromRows(rows, cols)
+}
+
+// listRow is a row of a cut list with its 1 based number in file
+type listRow struct {
+	num   int
+	cells []string
+}
+
+func specsFromRows(rows []listRow, cols Columns) ([]PieceSpec, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("cut list is empty")
+	}
+
+	// where every field is found
+	at := map[string]int{}
+	for i, h := range rows[0].cells {
+		at[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	col := func(header string) int {
+		if i, ok := at[strings.ToLower(header)]; ok {
+			return i
+		}
+		return -1
+	}
+	iw, ih, iq, ir, il := col(cols.Width), col(cols.Height), col(cols.Qty), col(cols.Rotate), col(cols.Label)
+	if iw < 0 || ih < 0 {
+		return nil, fmt.Errorf("cut list needs %q and %q columns", cols.Width, cols.Height)
+	}
+
+	var (
+		specs []PieceSpec
+		errs  CutListError
+	)
+	for _, r := range rows[1:] {
+		rowNum, row := r.num, r.cells
+		cell := func(i int) string {
+			if i < 0 || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+		if strings.Join(row, "") == "" {
+			continue
+		}
+		bad := func(err error) {
+			errs = append(errs, RowError{rowNum, err.Error()})
+		}
+
+		ps := PieceSpec{Qty: 1, Rotate: true, Label: cell(il)}
+		var err error
+		if ps.W, err = parseLength(cell(iw)); err != nil || ps.W <= 0 {
+			bad(fmt.Errorf("width %q must be a positive length", cell(iw)))
+			continue
+		}
+		if ps.H, err = parseLength(cell(ih)); err != nil || ps.H <= 0 {
+			bad(fmt.Errorf("height %q must be a positive length", cell(ih)))
+			continue
+		}
+		if q := cell(iq); q != "" {
+			// spreadsheets may keep integers as 4.0
+			f, err := strconv.ParseFloat(q, 64)
+			if err != nil || f < 1 || f != float64(int(f)) {
+				bad(fmt.Errorf("quantity %q must be a whole number greater than zero", q))
+				continue
+			}
+			ps.Qty = int(f)
+		}
+		if v := cell(ir); v != "" {
+			if ps.Rotate, err = parseYesNo(v); err != nil {
+				bad(fmt.Errorf("rotate %q must be yes or no", v))
+				continue
+			}
+		}
+		specs = append(specs, ps)
+	}
+
+	if len(errs) > 0 {
+		return specs, errs
+	}
+	return specs, nil
+}
+
+// Dimensions formats specs as dimension entries an Op takes
+func Dimensions(specs []PieceSpec) []string {
+	dd := []string{}
+	for _, ps := range specs {
+		dd = append(dd, ps.String())
+	}
+	return dd
+}
+
+// xlsx parts needed for reading cells
+type (
+	xlsxWorkbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xlsxRels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	xlsxStrings struct {
+		Items []struct {
+			T    string `xml:"t"`
+			Runs []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	xlsxSheet struct {
+		Rows []struct {
+			// empty rows are left out, so rows tell their number
+			Num   int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+)
+
+func xlsxRows(b []byte) ([]listRow, error) {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	decode := func(name string, v interface{}) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("xlsx: missing %s", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return xml.NewDecoder(rc).Decode(v)
+	}
+
+	// first worksheet, found through workbook relations
+	sheetName := "xl/worksheets/sheet1.xml"
+	var (
+		wb   xlsxWorkbook
+		rels xlsxRels
+	)
+	if decode("xl/workbook.xml", &wb) == nil && decode("xl/_rels/workbook.xml.rels", &rels) == nil && len(wb.Sheets) > 0 {
+		for _, rel := range rels.Rels {
+			if rel.ID == wb.Sheets[0].ID {
+				sheetName = path.Join("xl", strings.TrimPrefix(rel.Target, "/xl/"))
+				break
+			}
+		}
+	}
+
+	var shared []string
+	var sst xlsxStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decode("xl/sharedStrings.xml", &sst); err != nil {
+			return nil, err
+		}
+		for _, si := range sst.Items {
+			s := si.T
+			for _, run := range si.Runs {
+				s += run.T
+			}
+			shared = append(shared, s)
+		}
+	}
+
+	var sheet xlsxSheet
+	if err := decode(sheetName, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := []listRow{}
+	for _, row := range sheet.Rows {
+		num := row.Num
+		if num == 0 {
+			num = 1
+			if len(rows) > 0 {
+				num = rows[len(rows)-1].num + 1
+			}
+		}
+		cells := []string{}
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared) {
+					return nil, fmt.Errorf("xlsx: cell %s points to a missing string", c.Ref)
+				}
+				cells[col] = shared[n]
+			case "inlineStr":
+				cells[col] = c.Inline
+			default:
+				cells[col] = c.Value
+			}
+		}
+		rows = append(rows, listRow{num, cells})
+	}
+	return rows, nil
+}
+
+// columnIndex turns the letters of a cell reference like AB12 into a 0 based column
+func columnIndex(ref string) int {
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		n = n*26 + int(c-'A'+1)
+	}
+	return n - 1
+}
@@ -0,0 +1,81 @@
+package packong
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	cols, err := ParseColumns("width=W,height=H,qty=Pieces")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := "W,H,Pieces,Rotate,Label\n500,300,4,no,door\n\n24 1/2,36,,,\n"
+	got, err := ReadCSV(strings.NewReader(in), cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PieceSpec{
+		{W: 500, H: 300, Qty: 4, Label: "door"},
+		{W: 24.5, H: 36, Qty: 1, Rotate: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, expected %+v", got, want)
+	}
+
+	in = "width,height,qty\n500,x,1\n500,300,2\n500,300,0\n"
+	_, err = ReadCSV(strings.NewReader(in), DefaultColumns)
+	rows, ok := err.(CutListError)
+	if !ok || len(rows) != 2 || rows[0].Row != 2 || rows[1].Row != 4 {
+		t.Errorf("got %v, expected errors on rows 2 and 4", err)
+	}
+}
+
+// xlsx zips a workbook having a worksheet and shared strings
+func xlsx(t *testing.T, sheet, strings string) *bytes.Buffer {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/sharedStrings.xml":     strings,
+		"xl/worksheets/sheet1.xml": sheet,
+	}
+	for name, content := range parts {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	zw.Close()
+	return &buf
+}
+
+func TestReadXLSX(t *testing.T) {
+	buf := xlsx(t, `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="inlineStr"><is><t>label</t></is></c></row>
+<row r="2"><c r="A2"><v>600</v></c><c r="B2"><v>400.5</v></c><c r="D2" t="s"><v>2</v></c></row>
+</sheetData></worksheet>`, `<sst><si><t>width</t></si><si><t>height</t></si><si><r><t>sh</t></r><r><t>elf</t></r></si></sst>`)
+
+	got, err := ReadCutList("list.xlsx", buf, DefaultColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PieceSpec{{W: 600, H: 400.5, Qty: 1, Rotate: true, Label: "shelf"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, expected %+v", got, want)
+	}
+
+	// blank rows 3 and 4 are not written in file
+	buf = xlsx(t, `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2"><v>600</v></c><c r="B2"><v>400</v></c></row>
+<row r="5"><c r="A5"><v>600</v></c><c r="B5"><v>0</v></c></row>
+</sheetData></worksheet>`, `<sst><si><t>width</t></si><si><t>height</t></si></sst>`)
+	_, err = ReadCutList("list.xlsx", buf, DefaultColumns)
+	if rows, ok := err.(CutListError); !ok || len(rows) != 1 || rows[0].Row != 5 {
+		t.Errorf("got %v, expected error on row 5", err)
+	}
+}
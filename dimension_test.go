@@ -0,0 +1,46 @@
+package packong
+
+import (
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestLeaders(t *testing.T) {
+	// boxes too small for their dimensions, all on top row
+	op := NewOp(1000, 2000, []string{"10x10x3", "30x10"}, "mm").Outname("x").Appearance(true, true)
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, outs, err := op.Fit([][]*pak.Box{boxes}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leader := regexp.MustCompile(`<text x="([^"]*)" y="([^"]*)"  style="text-anchor:start;font-size:([^p]*)px`)
+	n := 0
+	for _, out := range outs {
+		for _, r := range out {
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range leader.FindAllStringSubmatch(string(b), -1) {
+				n++
+				x, _ := strconv.ParseFloat(m[1], 64)
+				y, _ := strconv.ParseFloat(m[2], 64)
+				fs, _ := strconv.ParseFloat(m[3], 64)
+				// text stands on its baseline, inside sheet, away from its dimension line
+				if x < 0 || y-fs < 0 {
+					t.Errorf("leader label at %v,%v sized %v is outside sheet", x, y, fs)
+				}
+			}
+		}
+	}
+	if n != 4 {
+		t.Errorf("got %d leader labels, expected 4", n)
+	}
+}
@@ -8,4 +8,5 @@ require (
 	github.com/innermond/pak v0.0.0-20190410062905-c33eb38e4a90
 	github.com/pkg/errors v0.8.1
 	github.com/tomasen/realip v0.0.0-20180522021738-f0c99a92ddce
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,103 @@
+package packong
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/innermond/packong/internal/svg"
+)
+
+// ArtOpener opens an artwork file by the name pieces refer it
+type ArtOpener func(name string) (io.ReadCloser, error)
+
+// Imposition places artwork of pieces into rendered sheets instead of coloured boxes;
+// embedded artwork is read by open, or from files when open is nil,
+// linked artwork is referred by its name
+func (op *Op) Imposition(embed bool, open ArtOpener) *Op {
+	op.impose = true
+	op.embed = embed
+	op.open = open
+	if op.open == nil {
+		op.open = func(name string) (io.ReadCloser, error) {
+			return os.Open(name)
+		}
+	}
+	return op
+}
+
+// artTypes are media types of artwork as data uri tells them
+var artTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".svg":  "image/svg+xml",
+}
+
+// href gives how a sheet refers artwork, reading it once when embedded
+func (op *Op) href(name string) (string, error) {
+	if !op.embed {
+		return name, nil
+	}
+	if h, ok := op.hrefs[name]; ok {
+		return h, nil
+	}
+	mt, ok := artTypes[strings.ToLower(filepath.Ext(name))]
+	if !ok {
+		return "", fmt.Errorf("artwork %q is neither png, jpeg nor svg", name)
+	}
+	f, err := op.open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	if op.hrefs == nil {
+		op.hrefs = map[string]string{}
+	}
+	op.hrefs[name] = "data:" + mt + ";base64," + base64.StdEncoding.EncodeToString(b)
+	return op.hrefs[name], nil
+}
+
+// arts gives artwork of pieces, placed into their trim boxes
+func (op *Op) arts(sh sheet) []svg.Art {
+	aa := []svg.Art{}
+	for _, box := range sh.boxes {
+		for _, pl := range placements(sh.pieces[box], box, 0) {
+			pc := pl.Piece
+			if pc == nil || pc.Art == "" {
+				continue
+			}
+			// artwork was read while making boxes
+			href, err := op.href(pc.Art)
+			if err != nil {
+				continue
+			}
+			a := svg.Art{
+				X:       pl.X + pc.Bleed,
+				Y:       pl.Y + pc.Bleed,
+				W:       pc.W,
+				H:       pc.H,
+				Rotated: pl.Rotated,
+				Href:    href,
+				IW:      pc.W,
+				IH:      pc.H,
+			}
+			if p := pc.Panel; p != nil {
+				// a panel shows its part of whole artwork
+				a.IX, a.IY, a.IW, a.IH = p.X, p.Y, p.w, p.h
+			} else {
+				a.R, a.Ellipse = pc.Radius, pc.Form != FormRect
+			}
+			aa = append(aa, a)
+		}
+	}
+	return aa
+}
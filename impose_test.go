@@ -0,0 +1,74 @@
+package packong
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestImposition(t *testing.T) {
+	open := func(name string) (io.ReadCloser, error) {
+		if name != "door.svg" {
+			return nil, errors.New("no such artwork")
+		}
+		return ioutil.NopCloser(strings.NewReader(`<svg width="500mm" height="300mm"></svg>`)), nil
+	}
+	op := NewOp(400, 2000, []string{"500x300x2 art=door.svg"}, "mm").Outname("x").Imposition(true, open)
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, outs, err := op.Fit([][]*pak.Box{boxes}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	for _, out := range outs {
+		for _, r := range out {
+			b, _ := ioutil.ReadAll(r)
+			s += string(b)
+		}
+	}
+	// too wide for sheet both pieces are turned, sharing one image
+	if n := strings.Count(s, "data:image/svg+xml;base64,"); n != 1 {
+		t.Errorf("got artwork embedded %d times, expected once", n)
+	}
+	if n := strings.Count(s, "rotate(90)"); n != 2 {
+		t.Errorf("got %d turned artwork, expected 2", n)
+	}
+	if strings.Contains(s, `id="blocks"`) {
+		t.Error("imposed sheet should not draw blocks")
+	}
+
+	_, err = NewOp(400, 2000, []string{"500x300 art=window.svg"}, "mm").Imposition(true, open).BoxesFromString()
+	if ve, ok := err.(ValidationError); !ok || len(ve) != 1 || ve[0].Field != "art" {
+		t.Errorf("got %v, expected missing artwork told", err)
+	}
+
+	// linked artwork names go escaped into sheet
+	link := `a&b "c".svg`
+	op = NewOp(400, 2000, []string{"500x300 art=" + strconv.Quote(link)}, "mm").Outname("x").Imposition(false, func(string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(`<svg width="500mm" height="300mm"></svg>`)), nil
+	})
+	boxes, err = op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, outs, err = op.Fit([][]*pak.Box{boxes}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range outs {
+		for fn, r := range out {
+			if err := xml.NewDecoder(r).Decode(new(struct{})); err != nil {
+				t.Errorf("%s: %v", fn, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Package barcode encodes text into barcodes drawn as a run of bars and spaces
+package barcode
+
+import "fmt"
+
+const (
+	startB = 104
+	stop   = 106
+	// modules of white space required on both sides of a barcode
+	QuietZone = 10
+)
+
+// patterns holds bar and space widths, in modules, for every code128 symbol;
+// each one starts with a bar
+var patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// Code128 encodes txt using code set B; it returns widths of alternating
+// bars and spaces, starting with a bar, quiet zones not included
+func Code128(txt string) ([]int, error) {
+	if txt == "" {
+		return nil, fmt.Errorf("code128: nothing to encode")
+	}
+
+	symbols := []int{startB}
+	checksum := startB
+	for i, c := range txt {
+		if c < 32 || c > 127 {
+			return nil, fmt.Errorf("code128: character %q at %d is out of code set B", c, i)
+		}
+		v := int(c) - 32
+		symbols = append(symbols, v)
+		checksum += v * len(symbols[1:])
+	}
+	symbols = append(symbols, checksum%103, stop)
+
+	widths := []int{}
+	for _, sym := range symbols {
+		for _, w := range patterns[sym] {
+			widths = append(widths, int(w-'0'))
+		}
+	}
+	return widths, nil
+}
+
+// Modules gives the full length of a barcode, quiet zones included
+func Modules(widths []int) int {
+	n := 2 * QuietZone
+	for _, w := range widths {
+		n += w
+	}
+	return n
+}
@@ -0,0 +1,42 @@
+package barcode
+
+import "testing"
+
+func TestPatterns(t *testing.T) {
+	for i, p := range patterns {
+		sum := 0
+		for _, w := range p {
+			sum += int(w - '0')
+		}
+		want := 11
+		if i == stop {
+			want = 13
+		}
+		if sum != want {
+			t.Errorf("pattern %d has %d modules, expected %d", i, sum, want)
+		}
+	}
+}
+
+func TestCode128(t *testing.T) {
+	widths, err := Code128("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// start, A, checksum and stop
+	if len(widths) != 6+6+6+7 {
+		t.Fatalf("got %d widths", len(widths))
+	}
+	// (104 + 33*1) % 103
+	checksum := ""
+	for _, w := range widths[12:18] {
+		checksum += string(rune('0' + w))
+	}
+	if checksum != patterns[34] {
+		t.Errorf("got checksum pattern %s, expected %s", checksum, patterns[34])
+	}
+
+	if _, err := Code128("ă"); err == nil {
+		t.Error("expected error for character out of code set")
+	}
+}
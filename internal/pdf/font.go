@@ -0,0 +1,104 @@
+package pdf
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// winAnsi gives codes of WinAnsiEncoding that are not Latin-1 ones
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// extra are letters Helvetica has but WinAnsiEncoding misses, mostly central european;
+// they take codes 1 and up, unused by WinAnsiEncoding, through font's differences
+var extra = []struct {
+	r     rune
+	glyph string
+}{
+	{'Ă', "Abreve"}, {'ă', "abreve"},
+	{'Ș', "Scommaaccent"}, {'ș', "scommaaccent"},
+	{'Ş', "Scedilla"}, {'ş', "scedilla"},
+	{'Ț', "Tcommaaccent"}, {'ț', "tcommaaccent"},
+	{'Ł', "Lslash"}, {'ł', "lslash"},
+	{'Ő', "Ohungarumlaut"}, {'ő', "ohungarumlaut"},
+	{'Ű', "Uhungarumlaut"}, {'ű', "uhungarumlaut"},
+	{'Č', "Ccaron"}, {'č', "ccaron"},
+	{'Ę', "Eogonek"}, {'ę', "eogonek"},
+	{'Ą', "Aogonek"}, {'ą', "aogonek"},
+	{'Ś', "Sacute"}, {'ś', "sacute"},
+	{'Ź', "Zacute"}, {'ź', "zacute"},
+	{'Ż', "Zdotaccent"}, {'ż', "zdotaccent"},
+	{'Ć', "Cacute"}, {'ć', "cacute"},
+	{'Ń', "Nacute"}, {'ń', "nacute"},
+}
+
+// same gives letters drawn by a glyph of extra
+var same = map[rune]rune{'Ţ': 'Ț', 'ţ': 'ț'}
+
+// differences is the /Differences array of font's encoding
+func differences() string {
+	s := "1"
+	for _, e := range extra {
+		s += " /" + e.glyph
+	}
+	return s
+}
+
+// Encode turns txt into codes of font's encoding; letters Helvetica lacks are an error
+func Encode(txt string) ([]byte, error) {
+	b := []byte{}
+	for _, r := range txt {
+		if s, ok := same[r]; ok {
+			r = s
+		}
+		switch c, ok := winAnsi[r]; {
+		case ok:
+			b = append(b, c)
+		case r >= 0x20 && r < 0x7f || r >= 0xa0 && r <= 0xff:
+			b = append(b, byte(r))
+		default:
+			found := false
+			for i, e := range extra {
+				if e.r == r {
+					b, found = append(b, byte(i+1)), true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("pdf: %q has %q that Helvetica can't show", txt, r)
+			}
+		}
+	}
+	return b, nil
+}
+
+// helvetica are widths of ascii glyphs, from space on, in thousandths of font size
+var helvetica = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 667, 500, 500, 334, 260, 334, 584,
+}
+
+// TextWidth tells how wide txt set in Helvetica of size is; accented letters
+// are taken as wide as an upper or lower case letter mostly is
+func TextWidth(txt string, size float64) float64 {
+	w := 0
+	for _, r := range txt {
+		switch {
+		case r >= 0x20 && r < 0x7f:
+			w += helvetica[r-0x20]
+		case unicode.IsUpper(r):
+			w += 722
+		default:
+			w += 556
+		}
+	}
+	return float64(w) * size / 1000
+}
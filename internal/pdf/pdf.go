@@ -0,0 +1,116 @@
+// Package pdf writes simple vector pdf documents: rectangles, lines and text
+// set in the standard Helvetica font, western and central european letters only;
+// coordinates are in millimeters measured from top left corner of a page
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// points in a millimeter
+const mm = 72 / 25.4
+
+type page struct {
+	w, h    float64
+	content bytes.Buffer
+}
+
+// Doc is a pdf document under construction
+type Doc struct {
+	pages []*page
+}
+
+// AddPage starts a new page w wide and h high; drawing goes on it from now on
+func (d *Doc) AddPage(w, h float64) {
+	d.pages = append(d.pages, &page{w: w, h: h})
+}
+
+func (d *Doc) current() *page {
+	if len(d.pages) == 0 {
+		d.AddPage(210, 297)
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Rect draws a rectangle; filled with black when fill is true, stroked otherwise
+func (d *Doc) Rect(x, y, w, h float64, fill bool) {
+	p := d.current()
+	op := "S"
+	if fill {
+		op = "f"
+	}
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f %.3f re %s\n", x*mm, (p.h-y-h)*mm, w*mm, h*mm, op)
+}
+
+// Line draws a line of width lw
+func (d *Doc) Line(x1, y1, x2, y2, lw float64) {
+	p := d.current()
+	fmt.Fprintf(&p.content, "%.3f w %.3f %.3f m %.3f %.3f l S\n", lw*mm, x1*mm, (p.h-y1)*mm, x2*mm, (p.h-y2)*mm)
+}
+
+// Text writes txt having baseline at y; size is in millimeters.
+// It fails for text Helvetica can't show, see Encode
+func (d *Doc) Text(x, y, size float64, txt string) error {
+	b, err := Encode(txt)
+	if err != nil {
+		return err
+	}
+	p := d.current()
+	fmt.Fprintf(&p.content, "BT /F1 %.3f Tf %.3f %.3f Td (%s) Tj ET\n", size*mm, x*mm, (p.h-y)*mm, escape(b))
+	return nil
+}
+
+// escape writes codes as a pdf string; those not printable ascii go as octal
+func escape(b []byte) string {
+	s := ""
+	for _, c := range b {
+		switch {
+		case c == '\\' || c == '(' || c == ')':
+			s += `\` + string(c)
+		case c < 0x20 || c >= 0x7f:
+			s += fmt.Sprintf(`\%03o`, c)
+		default:
+			s += string(c)
+		}
+	}
+	return s
+}
+
+// Bytes renders the document
+func (d *Doc) Bytes() []byte {
+	var (
+		b       bytes.Buffer
+		offsets []int
+	)
+	obj := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	d.current()
+	b.WriteString("%PDF-1.4\n")
+	// 1 catalog, 2 pages, 3 font, then a page and its content for every page
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := []string{}
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+2*i))
+	}
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding << /Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences [%s] >> >>", differences()))
+	for i, p := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.3f %.3f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			p.w*mm, p.h*mm, 5+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return b.Bytes()
+}
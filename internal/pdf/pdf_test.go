@@ -0,0 +1,59 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	b, err := Encode("Ușă €é")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ș and ă go through differences, € through WinAnsiEncoding, é is latin-1
+	if want := []byte{'U', 4, 2, ' ', 0x80, 0xe9}; !bytes.Equal(b, want) {
+		t.Errorf("got %v, expected %v", b, want)
+	}
+	cedilla, _ := Encode("Ţ")
+	comma, _ := Encode("Ț")
+	if !bytes.Equal(cedilla, comma) {
+		t.Errorf("got %v, expected Ţ drawn as Ț %v", cedilla, comma)
+	}
+	if _, err := Encode("日本"); err == nil {
+		t.Error("expected error for letters Helvetica lacks")
+	}
+}
+
+func TestBytes(t *testing.T) {
+	var d Doc
+	d.AddPage(100, 50)
+	d.Rect(10, 10, 20, 5, true)
+	if err := d.Text(10, 30, 4, `a(b)\ș`); err != nil {
+		t.Fatal(err)
+	}
+	d.AddPage(100, 50)
+	if err := d.Text(10, 30, 4, "日"); err == nil {
+		t.Error("expected error for letters Helvetica lacks")
+	}
+	b := d.Bytes()
+
+	for _, want := range []string{`(a\(b\)\\\004) Tj`, "/Count 2", "/Differences [1 /Abreve /abreve /Scommaaccent"} {
+		if !bytes.Contains(b, []byte(want)) {
+			t.Errorf("expected %s in pdf", want)
+		}
+	}
+	// every object is where xref tells
+	xref := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(b, -1)
+	if len(xref) != 3+2*2 {
+		t.Fatalf("got %d objects", len(xref))
+	}
+	for i, m := range xref {
+		off, _ := strconv.Atoi(string(m[1]))
+		if !bytes.HasPrefix(b[off:], []byte(fmt.Sprintf("%d 0 obj", i+1))) {
+			t.Errorf("object %d is not at %d", i+1, off)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package svg
+
+import (
+	"fmt"
+	"html"
+)
+
+// Art is artwork placed into a trim box whose top left corner is X, Y; W by H
+// is trim box as artwork lays unturned, Rotated turns it clockwise.
+// Image is IW by IH and trim box starts at IX, IY on it, as panels do
+type Art struct {
+	X, Y, W, H     float64
+	Rotated        bool
+	Href           string
+	IW, IH, IX, IY float64
+	// outline clipping artwork: corner radius or an ellipse
+	R       float64
+	Ellipse bool
+}
+
+// Artwork places images clipped to their trim boxes; images and clips used
+// by many pieces are defined once
+func Artwork(aa []Art, plain bool) string {
+	type image struct {
+		href   string
+		iw, ih float64
+	}
+	type clip struct {
+		w, h, r float64
+		ellipse bool
+	}
+	images, clips := map[image]int{}, map[clip]int{}
+	defs, g := "<defs>", ""
+	for _, a := range aa {
+		im := image{a.Href, a.IW, a.IH}
+		ni, ok := images[im]
+		if !ok {
+			ni = len(images) + 1
+			images[im] = ni
+			defs += fmt.Sprintf(`
+<image id="art-%d" width="%f" height="%f" preserveAspectRatio="xMidYMid slice" xlink:href="%s" />`, ni, a.IW, a.IH, html.EscapeString(a.Href))
+		}
+		cl := clip{a.W, a.H, a.R, a.Ellipse}
+		nc, ok := clips[cl]
+		if !ok {
+			nc = len(clips) + 1
+			clips[cl] = nc
+			defs += fmt.Sprintf(`
+<clipPath id="trim-%d">%s
+</clipPath>`, nc, roundShape(0, 0, a.W, a.H, a.R, a.Ellipse, "fill:black"))
+		}
+
+		transform := fmt.Sprintf("translate(%f %f)", a.X, a.Y)
+		if a.Rotated {
+			// turned box is as wide as artwork is high
+			transform = fmt.Sprintf("translate(%f %f) rotate(90)", a.X+a.H, a.Y)
+		}
+		g += fmt.Sprintf(`
+<g transform="%s" clip-path="url(#trim-%d)"><use xlink:href="#art-%d" x="%f" y="%f" /></g>`, transform, nc, ni, -a.IX, -a.IY)
+	}
+	defs += "\n</defs>"
+
+	gs := GroupStart("id=\"artwork\"")
+	if !plain {
+		gs = GroupStart("id=\"artwork\"", "inkscape:label=\"artwork\"", "inkscape:groupmode=\"layer\"")
+	}
+	return GroupEnd(gs + defs + g)
+}
@@ -0,0 +1,146 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/innermond/pak"
+)
+
+const (
+	// arrow heads used by dimension lines
+	arrowdefs = `
+<defs>
+<marker id="arrow-start" viewBox="0 0 10 10" refX="0" refY="5" markerWidth="6" markerHeight="6" orient="auto">
+<path d="M 10 0 L 0 5 L 10 10 z" style="fill:#000" />
+</marker>
+<marker id="arrow-end" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto">
+<path d="M 0 0 L 10 5 L 0 10 z" style="fill:#000" />
+</marker>
+</defs>`
+
+	// average width of a digit relative to font size
+	charRatio = 0.6
+)
+
+// ArrowDefs holds arrow heads needed by dimension lines; it must be rendered once per svg
+func ArrowDefs() string {
+	return arrowdefs
+}
+
+// FontRange bounds font size of dimensions; values are in real units
+type FontRange struct {
+	Min, Max float64
+}
+
+func (fr FontRange) clamp(fs float64) float64 {
+	return math.Max(fr.Min, math.Min(fr.Max, fs))
+}
+
+func textLen(txt string, fs float64) float64 {
+	return float64(len(txt)) * fs * charRatio
+}
+
+// DimLine draws a line with arrows on both ends and txt centered along it
+func DimLine(x1, y1, x2, y2 float64, txt string, fs float64) string {
+	sw := fs / 10
+	s := fmt.Sprintf(`
+<line x1="%f" y1="%f" x2="%f" y2="%f" style="stroke:#000;stroke-width:%.3f" marker-start="url(#arrow-start)" marker-end="url(#arrow-end)" />`,
+		x1, y1, x2, y2, sw)
+
+	xt, yt := (x1+x2)/2, (y1+y2)/2
+	rotation := ""
+	if x1 == x2 {
+		// vertical text sits on the left of line
+		xt -= fs / 4
+		rotation = fmt.Sprintf(" transform=\"rotate(-90, %.2f,%.2f)\" ", xt, yt)
+	} else {
+		// horizontal text sits above line
+		yt -= fs / 4
+	}
+	s += Text(xt, yt, rotation, txt, fmt.Sprintf("text-anchor:middle;font-size:%.2fpx;fill:#000", fs))
+	return s
+}
+
+// Leader draws a line from a box to a text placed outside of it
+func Leader(x1, y1, x2, y2 float64, txt string, fs float64) string {
+	s := Line(x1, y1, x2, y2, fmt.Sprintf("stroke:#000;stroke-width:%.3f", fs/10))
+	s += Text(x2, y2, "", txt, fmt.Sprintf("text-anchor:start;font-size:%.2fpx;fill:#000", fs))
+	return s
+}
+
+// Dimensions renders dimension lines for every block; blocks too small for
+// their own dimensions get a label placed outside with a leader line, kept
+// inside a sheet width wide and below its top
+func Dimensions(blocks []*pak.Box, cutwidth, width float64, fr FontRange, plain bool) string {
+	gt := GroupStart("id=\"dimensions\"")
+	if !plain {
+		gt = GroupStart("id=\"dimensions\"", "inkscape:label=\"dimensions\"", "inkscape:groupmode=\"layer\"")
+	}
+
+	for _, blk := range blocks {
+		if blk == nil {
+			continue
+		}
+		// real dimensions do not include the cut
+		w, h := blk.W-0.5*cutwidth, blk.H-0.5*cutwidth
+		tw, th := fmt.Sprintf("%.2f", w), fmt.Sprintf("%.2f", h)
+		fs := fr.clamp(math.Min(blk.W, blk.H) / 6)
+		// room taken by a dimension line and its text
+		gap := 1.5 * fs
+
+		fitW := textLen(tw, fs)+2*fs < blk.W && 2*gap < blk.H
+		fitH := textLen(th, fs)+2*fs < blk.H && 2*gap < blk.W
+		if fitW {
+			gt += DimLine(blk.X, blk.Y+gap, blk.X+blk.W, blk.Y+gap, tw, fs)
+		}
+		if fitH {
+			gt += DimLine(blk.X+gap, blk.Y, blk.X+gap, blk.Y+blk.H, th, fs)
+		}
+		if fitW && fitH {
+			if blk.Rotated {
+				gt += Text(blk.X+blk.W/2, blk.Y+blk.H/2, "", "R", fmt.Sprintf("text-anchor:middle;font-size:%.2fpx;fill:#000", fs))
+			}
+			continue
+		}
+
+		// too small, label goes outside
+		txt := tw + "x" + th
+		if blk.Rotated {
+			txt += "xR"
+		}
+		fs = fr.Min
+		xc, yc := blk.X+blk.W/2, blk.Y+blk.H/2
+		// label past right edge of sheet is pulled back over it
+		xl := math.Max(0, math.Min(blk.X+blk.W+fs, width-textLen(txt, fs)))
+		// label above sheet would cross its dimension line, so it goes under box
+		yl := blk.Y - fs
+		if yl-fs < 0 {
+			yl = blk.Y + blk.H + fs
+		}
+		gt += Leader(xc, yc, xl, yl, txt, fs)
+	}
+
+	return GroupEnd(gt)
+}
+
+// SheetDimensions draws overall dimensions along top and left edges of a sheet;
+// lines are placed outside of sheet at a distance of pad
+func SheetDimensions(w, h, pad float64, unit string, fr FontRange, plain bool) string {
+	g := GroupStart("id=\"sheet_dimensions\"")
+	if !plain {
+		g = GroupStart("id=\"sheet_dimensions\"", "inkscape:label=\"sheet_dimensions\"", "inkscape:groupmode=\"layer\"")
+	}
+	fs := fr.clamp(pad / 3)
+	sw := fmt.Sprintf("stroke:#000;stroke-width:%.3f", fs/10)
+	// extension lines
+	g += Line(0.0, 0.0, 0.0, -pad, sw)
+	g += Line(w, 0.0, w, -pad, sw)
+	g += Line(0.0, 0.0, -pad, 0.0, sw)
+	g += Line(0.0, h, -pad, h, sw)
+
+	g += DimLine(0.0, -pad/2, w, -pad/2, fmt.Sprintf("%.2f %s", w, unit), fs)
+	g += DimLine(-pad/2, 0.0, -pad/2, h, fmt.Sprintf("%.2f %s", h, unit), fs)
+
+	return GroupEnd(g)
+}
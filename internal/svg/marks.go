@@ -0,0 +1,29 @@
+package svg
+
+import "fmt"
+
+// Mark is a registration mark centred at X, Y; a square unless Circle
+type Mark struct {
+	X, Y, Size float64
+	Circle     bool
+}
+
+// Marks draws crop marks, given as lines x1, y1, x2, y2, and registration marks
+func Marks(crop [][4]float64, reg []Mark, plain bool) string {
+	g := GroupStart("id=\"marks\"")
+	if !plain {
+		g = GroupStart("id=\"marks\"", "inkscape:label=\"marks\"", "inkscape:groupmode=\"layer\"")
+	}
+	for _, l := range crop {
+		g += Line(l[0], l[1], l[2], l[3], "stroke:black;stroke-width:0.25")
+	}
+	for _, m := range reg {
+		if m.Circle {
+			g += fmt.Sprintf(`
+<circle cx="%f" cy="%f" r="%f" style="fill:black;stroke:none" />`, m.X, m.Y, m.Size/2)
+			continue
+		}
+		g += Rect(m.X-m.Size/2, m.Y-m.Size/2, m.Size, m.Size, "fill:black;stroke:none")
+	}
+	return GroupEnd(g)
+}
@@ -8,15 +8,34 @@ import (
 	"github.com/innermond/pak"
 )
 
-func aproximateHeightText(numchar int, w float64) float64 {
-	wchar := w / float64(numchar)
-	return math.Floor(1.5*wchar*100.0) / 100
-}
-
 var (
 	strokeStyle = "stroke: gray;stroke-width:2;fill:none"
+
+	// fills telling how a block relates to the big box edges
+	fillFirst = "fill:magenta;stroke:none"
+	fillTop   = "fill:red;stroke:none"
+	fillLeft  = "fill:green;stroke:none"
+	fillOther = "fill:#eee;stroke:none"
+	fillReal  = "fill:white;stroke:none"
+	fillBand  = "fill:orange;stroke:none"
 )
 
+// blockFill tells the first block and those on top or left margins apart from the others
+func blockFill(i int, blk *pak.Box, top, left float64) string {
+	switch {
+	case i == 0:
+		return fillFirst
+	// blocks on the top edge must be shortened on height by a expand = half cutwidth
+	case blk.Y == top:
+		return fillTop
+	// blocks on the left edge must be shortened on width by a expand = half cutwidth
+	case blk.X == left:
+		return fillLeft
+	}
+	// blocks that do not touch any big box edges keeps their expanded dimensions
+	return fillOther
+}
+
 func style(fill string, outline bool) string {
 	if outline {
 		return strokeStyle
@@ -24,7 +43,8 @@ func style(fill string, outline bool) string {
 	return fill
 }
 
-func Out(blocks []*pak.Box, cutwidth float64, topleftmargin float64, widthSvg float64, unit string, plain bool, showDim bool, outline bool) (string, error) {
+// Out draws blocks; those laying on top or left margins are told apart
+func Out(blocks []*pak.Box, cutwidth float64, top, left float64, widthSvg float64, plain bool, outline bool) (string, error) {
 	if len(blocks) == 0 {
 		return "", errors.New("no blocks")
 	}
@@ -34,85 +54,19 @@ func Out(blocks []*pak.Box, cutwidth float64, topleftmargin float64, widthSvg fl
 		gb = GroupStart("id=\"blocks\"", "inkscape:label=\"blocks\"", "inkscape:groupmode=\"layer\"")
 	}
 
-	// first block
-	blk := blocks[0]
-	gb += Rect(blk.X,
-		blk.Y,
-		blk.W,
-		blk.H,
-		style("fill:magenta;stroke:none", outline),
-	)
-
-	for _, blk := range blocks[1:] {
-		if blk != nil {
-			// blocks on the top edge must be shortened on height by a expand = half cutwidth
-			if blk.Y == topleftmargin {
-				gb += Rect(blk.X,
-					blk.Y,
-					blk.W,
-					blk.H,
-					style("fill:red;stroke:none", outline),
-				)
-				continue
-			}
-			// blocks on the left edge must be shortened on width by a expand = half cutwidth
-			if blk.X == topleftmargin {
-				gb += Rect(blk.X,
-					blk.Y,
-					blk.W,
-					blk.H,
-					style("fill:green;stroke:none", outline),
-				)
-				continue
-			}
-			// blocks that do not touch any big box edges keeps their expanded dimensions
-			gb += Rect(blk.X,
-				blk.Y,
-				blk.W,
-				blk.H,
-				style("fill:#eee;stroke:none", outline),
-			)
-		} else {
+	for i, blk := range blocks {
+		if blk == nil {
 			return "", errors.New("unexpected unfit block")
 		}
+		gb += Rect(blk.X,
+			blk.Y,
+			blk.W,
+			blk.H,
+			style(blockFill(i, blk, top, left), outline),
+		)
 	}
 	gb = GroupEnd(gb)
 
-	gt := ""
-	if showDim {
-		gt = GroupStart("id=\"dimensions\"")
-		if !plain {
-			gt = GroupStart("id=\"dimensions\"", "inkscape:label=\"dimensions\"", "inkscape:groupmode=\"layer\"")
-		}
-		for _, blk := range blocks {
-			if blk != nil {
-				x := fmt.Sprintf("%.2fx%.2f", blk.W-0.5*cutwidth, blk.H-0.5*cutwidth)
-				if blk.Rotated {
-					x += "xR"
-				}
-				xt := blk.X + blk.W/2
-				yt := blk.Y + blk.H/2
-				rotation := ""
-				lendim := blk.W
-				// 4 represent 2 decimal points and 2 white spaces of a _number.decimalsxnumber.decimals_
-				// that should be fit in lendim
-				lenx := float64(len(x) + 4.0)
-				if blk.H > blk.W {
-					rotation = fmt.Sprintf(" transform=\"rotate(90, %.2f,%.2f)\" ", xt, yt)
-					lendim = blk.H
-				}
-				// assume height of a letter is 2 than its width
-				// assume 16px represents 100%
-				y := 0.5 * math.Floor(lendim/lenx)
-				gt += Text(xt, yt, rotation,
-					x, "text-anchor:middle;font-size:"+fmt.Sprintf("%.2f%s", y, unit)+";fill:#000")
-			} else {
-				return "", errors.New("unexpected unfit block")
-			}
-		}
-		gt = GroupEnd(gt)
-	}
-
 	gi := ""
 	var d float64 = cutwidth * 0.25
 	if d != 0.0 {
@@ -129,28 +83,28 @@ func Out(blocks []*pak.Box, cutwidth float64, topleftmargin float64, widthSvg fl
 			blk.Y+d,
 			blk.W-d2,
 			blk.H-d2,
-			style("fill:white;stroke:none", outline),
+			style(fillReal, outline),
 		)
 
 		for _, blk := range blocks[1:] {
 			if blk != nil {
 				// blocks on the top edge must be shortened on height by a expand = half cutwidth
-				if blk.Y == topleftmargin {
+				if blk.Y == top {
 					gi += Rect(blk.X+d,
 						blk.Y+d,
 						blk.W-d2,
 						blk.H-d2,
-						style("fill:white;stroke:none", outline),
+						style(fillReal, outline),
 					)
 					continue
 				}
 				// blocks on the left edge must be shortened on width by a expand = half cutwidth
-				if blk.X == topleftmargin {
+				if blk.X == left {
 					gi += Rect(blk.X+d,
 						blk.Y+d,
 						blk.W-d2,
 						blk.H-d2,
-						style("fill:white;stroke:none", outline),
+						style(fillReal, outline),
 					)
 					continue
 				}
@@ -159,7 +113,7 @@ func Out(blocks []*pak.Box, cutwidth float64, topleftmargin float64, widthSvg fl
 					blk.Y+d,
 					blk.W-d2,
 					blk.H-d2,
-					style("fill:white;stroke:none", outline),
+					style(fillReal, outline),
 				)
 			} else {
 				return "", errors.New("unexpected unfit block")
@@ -168,5 +122,36 @@ func Out(blocks []*pak.Box, cutwidth float64, topleftmargin float64, widthSvg fl
 		gi = GroupEnd(gi)
 	}
 
-	return gb + gi + gt, nil
+	return gb + gi, nil
+}
+
+// Banding draws edges having tape as thick lines on a sheet w wide
+func Banding(lines [][4]float64, w float64, plain bool) string {
+	g := GroupStart("id=\"banding\"")
+	if !plain {
+		g = GroupStart("id=\"banding\"", "inkscape:label=\"banding\"", "inkscape:groupmode=\"layer\"")
+	}
+	for _, l := range lines {
+		// as thick as a tenth of edge but no more than a 200th of sheet's width
+		sw := math.Min(w/200, math.Max(math.Abs(l[2]-l[0]), math.Abs(l[3]-l[1]))/10)
+		g += Line(l[0], l[1], l[2], l[3], fmt.Sprintf("stroke:orange;stroke-width:%.2f", sw))
+	}
+	return GroupEnd(g)
+}
+
+// Bleed outlines bleed boxes, dashed, and trim boxes of pieces; boxes are x, y, w, h
+func Bleed(bleed, trim [][4]float64, plain bool) string {
+	g := GroupStart("id=\"bleed\"")
+	if !plain {
+		g = GroupStart("id=\"bleed\"", "inkscape:label=\"bleed\"", "inkscape:groupmode=\"layer\"")
+	}
+	for _, b := range bleed {
+		sw := math.Min(2, math.Min(b[2], b[3])/100)
+		g += Rect(b[0], b[1], b[2], b[3], fmt.Sprintf("fill:none;stroke:red;stroke-width:%.2f;stroke-dasharray:%.2f", sw, 4*sw))
+	}
+	for _, t := range trim {
+		sw := math.Min(2, math.Min(t[2], t[3])/100)
+		g += Rect(t[0], t[1], t[2], t[3], fmt.Sprintf("fill:none;stroke:black;stroke-width:%.2f", sw))
+	}
+	return GroupEnd(g)
 }
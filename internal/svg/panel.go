@@ -0,0 +1,26 @@
+package svg
+
+import "fmt"
+
+var fillOverlap = "fill:blue;fill-opacity:0.3;stroke:none"
+
+// PanelMark is the number of a tiled panel written centred at X, Y
+type PanelMark struct {
+	X, Y, Size float64
+	Text       string
+}
+
+// Panels shades overlap zones, given as x, y, w, h, of tiled panels and writes panel numbers
+func Panels(zones [][4]float64, marks []PanelMark, plain bool) string {
+	g := GroupStart("id=\"panels\"")
+	if !plain {
+		g = GroupStart("id=\"panels\"", "inkscape:label=\"panels\"", "inkscape:groupmode=\"layer\"")
+	}
+	for _, z := range zones {
+		g += Rect(z[0], z[1], z[2], z[3], fillOverlap)
+	}
+	for _, m := range marks {
+		g += Text(m.X, m.Y+m.Size/3, "", m.Text, fmt.Sprintf("text-anchor:middle;font-size:%.2fpx;fill:#00f", m.Size))
+	}
+	return GroupEnd(g)
+}
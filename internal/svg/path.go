@@ -0,0 +1,320 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Point is a point of an outline
+type Point struct {
+	X, Y float64
+}
+
+// Polygon is a closed outline
+type Polygon []Point
+
+// segments a curve is flattened into
+const curveSteps = 16
+
+// ParsePath reads svg path data into polygons, one for every subpath;
+// curves and arcs are flattened into lines
+func ParsePath(d string) ([]Polygon, error) {
+	tokens, err := pathTokens(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		polys []Polygon
+		cur   Polygon
+		// current point, subpath start and last control point
+		p, start, ctrl Point
+		cmd, prev      byte
+	)
+	closeSub := func() {
+		if len(cur) > 2 {
+			polys = append(polys, cur)
+		}
+		cur = nil
+	}
+	i := 0
+	num := func() (float64, error) {
+		if i >= len(tokens) {
+			return 0, fmt.Errorf("path %q ends while a number is expected", d)
+		}
+		v, err := strconv.ParseFloat(tokens[i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("path %q: %q is not a number", d, tokens[i])
+		}
+		i++
+		return v, nil
+	}
+	nums := func(n int) ([]float64, error) {
+		vv := make([]float64, n)
+		for k := range vv {
+			v, err := num()
+			if err != nil {
+				return nil, err
+			}
+			vv[k] = v
+		}
+		return vv, nil
+	}
+	lineTo := func(q Point) {
+		if len(cur) == 0 {
+			cur = Polygon{p}
+		}
+		cur = append(cur, q)
+		p = q
+	}
+
+	for i < len(tokens) {
+		t := tokens[i]
+		if c := t[0]; len(t) == 1 && unicode.IsLetter(rune(c)) {
+			cmd = c
+			i++
+		} else if cmd == 0 {
+			return nil, fmt.Errorf("path %q must start with a command", d)
+		}
+		rel := unicode.IsLower(rune(cmd))
+		at := func(x, y float64) Point {
+			if rel {
+				return Point{p.X + x, p.Y + y}
+			}
+			return Point{x, y}
+		}
+
+		switch unicode.ToUpper(rune(cmd)) {
+		case 'M':
+			vv, err := nums(2)
+			if err != nil {
+				return nil, err
+			}
+			closeSub()
+			p = at(vv[0], vv[1])
+			start = p
+			cur = Polygon{p}
+			// coordinates following a move are lines
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L':
+			vv, err := nums(2)
+			if err != nil {
+				return nil, err
+			}
+			lineTo(at(vv[0], vv[1]))
+		case 'H':
+			v, err := num()
+			if err != nil {
+				return nil, err
+			}
+			if rel {
+				v += p.X
+			}
+			lineTo(Point{v, p.Y})
+		case 'V':
+			v, err := num()
+			if err != nil {
+				return nil, err
+			}
+			if rel {
+				v += p.Y
+			}
+			lineTo(Point{p.X, v})
+		case 'C', 'S':
+			var c1, c2, q Point
+			if unicode.ToUpper(rune(cmd)) == 'C' {
+				vv, err := nums(6)
+				if err != nil {
+					return nil, err
+				}
+				c1, c2, q = at(vv[0], vv[1]), at(vv[2], vv[3]), at(vv[4], vv[5])
+			} else {
+				vv, err := nums(4)
+				if err != nil {
+					return nil, err
+				}
+				c1 = p
+				if up := unicode.ToUpper(rune(prev)); up == 'C' || up == 'S' {
+					c1 = Point{2*p.X - ctrl.X, 2*p.Y - ctrl.Y}
+				}
+				c2, q = at(vv[0], vv[1]), at(vv[2], vv[3])
+			}
+			p0 := p
+			for k := 1; k <= curveSteps; k++ {
+				t := float64(k) / curveSteps
+				u := 1 - t
+				lineTo(Point{
+					u*u*u*p0.X + 3*u*u*t*c1.X + 3*u*t*t*c2.X + t*t*t*q.X,
+					u*u*u*p0.Y + 3*u*u*t*c1.Y + 3*u*t*t*c2.Y + t*t*t*q.Y,
+				})
+			}
+			ctrl = c2
+		case 'Q', 'T':
+			var c, q Point
+			if unicode.ToUpper(rune(cmd)) == 'Q' {
+				vv, err := nums(4)
+				if err != nil {
+					return nil, err
+				}
+				c, q = at(vv[0], vv[1]), at(vv[2], vv[3])
+			} else {
+				vv, err := nums(2)
+				if err != nil {
+					return nil, err
+				}
+				c = p
+				if up := unicode.ToUpper(rune(prev)); up == 'Q' || up == 'T' {
+					c = Point{2*p.X - ctrl.X, 2*p.Y - ctrl.Y}
+				}
+				q = at(vv[0], vv[1])
+			}
+			p0 := p
+			for k := 1; k <= curveSteps; k++ {
+				t := float64(k) / curveSteps
+				u := 1 - t
+				lineTo(Point{u*u*p0.X + 2*u*t*c.X + t*t*q.X, u*u*p0.Y + 2*u*t*c.Y + t*t*q.Y})
+			}
+			ctrl = c
+		case 'A':
+			vv, err := nums(7)
+			if err != nil {
+				return nil, err
+			}
+			q := at(vv[5], vv[6])
+			for _, a := range arc(p, q, vv[0], vv[1], vv[2], vv[3] != 0, vv[4] != 0) {
+				lineTo(a)
+			}
+		case 'Z':
+			closeSub()
+			p = start
+		default:
+			return nil, fmt.Errorf("path %q has unknown command %q", d, cmd)
+		}
+		prev = cmd
+	}
+	closeSub()
+	if len(polys) == 0 {
+		return nil, fmt.Errorf("path %q outlines nothing", d)
+	}
+	return polys, nil
+}
+
+// pathTokens splits path data into commands and numbers
+func pathTokens(d string) ([]string, error) {
+	var (
+		tokens []string
+		b      strings.Builder
+	)
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, b.String())
+			b.Reset()
+		}
+	}
+	for _, c := range d {
+		switch {
+		case unicode.IsLetter(c) && c != 'e' && c != 'E':
+			flush()
+			tokens = append(tokens, string(c))
+		case c == ' ' || c == ',' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+		case c == '-' || c == '+':
+			// a sign starts a number unless it follows an exponent
+			if s := b.String(); s != "" && !strings.HasSuffix(s, "e") && !strings.HasSuffix(s, "E") {
+				flush()
+			}
+			b.WriteRune(c)
+		case c == '.':
+			// a second dot starts a number, as in 0.5.5
+			if strings.Contains(b.String(), ".") {
+				flush()
+			}
+			b.WriteRune(c)
+		case unicode.IsDigit(c) || c == 'e' || c == 'E':
+			b.WriteRune(c)
+		default:
+			return nil, fmt.Errorf("path %q has unexpected %q", d, c)
+		}
+	}
+	flush()
+	return tokens, nil
+}
+
+// arc flattens an elliptical arc from p to q as svg describes it
+func arc(p, q Point, rx, ry, phi float64, large, sweep bool) []Point {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || p == q {
+		return []Point{q}
+	}
+	sin, cos := math.Sincos(phi * math.Pi / 180)
+	dx, dy := (p.X-q.X)/2, (p.Y-q.Y)/2
+	x1, y1 := cos*dx+sin*dy, -sin*dx+cos*dy
+	// radii too small are scaled up
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	k := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		k = -k
+	}
+	cx1, cy1 := k*rx*y1/ry, -k*ry*x1/rx
+	cx, cy := cos*cx1-sin*cy1+(p.X+q.X)/2, sin*cx1+cos*cy1+(p.Y+q.Y)/2
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	t1 := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	dt := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if !sweep && dt > 0 {
+		dt -= 2 * math.Pi
+	} else if sweep && dt < 0 {
+		dt += 2 * math.Pi
+	}
+
+	pp := []Point{}
+	for k := 1; k <= curveSteps; k++ {
+		t := t1 + dt*float64(k)/curveSteps
+		x, y := rx*math.Cos(t), ry*math.Sin(t)
+		pp = append(pp, Point{cos*x - sin*y + cx, sin*x + cos*y + cy})
+	}
+	pp[len(pp)-1] = q
+	return pp
+}
+
+// PathData writes polygons as svg path data
+func PathData(polys []Polygon) string {
+	var b strings.Builder
+	for _, poly := range polys {
+		for i, pt := range poly {
+			c := "L"
+			if i == 0 {
+				c = "M"
+			}
+			fmt.Fprintf(&b, "%s%.3f %.3f ", c, pt.X, pt.Y)
+		}
+		b.WriteString("Z ")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Outlines draws polygons of every shape as a path of its own
+func Outlines(shapes [][]Polygon, outline bool, plain bool) string {
+	g := GroupStart("id=\"outlines\"")
+	if !plain {
+		g = GroupStart("id=\"outlines\"", "inkscape:label=\"outlines\"", "inkscape:groupmode=\"layer\"")
+	}
+	for _, polys := range shapes {
+		g += fmt.Sprintf(`
+<path d="%s" style="%s" />`, PathData(polys), style("fill:#eee;fill-rule:evenodd;stroke:black;stroke-width:0.5", outline))
+	}
+	return GroupEnd(g)
+}
@@ -0,0 +1,45 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+)
+
+// Round is a piece outlined by an ellipse or a rounded rectangle fitting X, Y, W, H
+type Round struct {
+	X, Y, W, H float64
+	// corner radius of a rectangle
+	R       float64
+	Ellipse bool
+	// print past outline, drawn dashed
+	Bleed float64
+}
+
+// Rounds draws real outlines of round pieces over their blocks
+func Rounds(rr []Round, plain bool, outline bool) string {
+	g := GroupStart("id=\"shapes\"")
+	if !plain {
+		g = GroupStart("id=\"shapes\"", "inkscape:label=\"shapes\"", "inkscape:groupmode=\"layer\"")
+	}
+	for _, r := range rr {
+		sw := math.Min(2, math.Min(r.W, r.H)/100)
+		g += roundShape(r.X, r.Y, r.W, r.H, r.R, r.Ellipse, style(fmt.Sprintf("fill:white;stroke:black;stroke-width:%.2f", sw), outline))
+		if b := r.Bleed; b > 0 {
+			rb := r.R
+			if rb > 0 {
+				rb += b
+			}
+			g += roundShape(r.X-b, r.Y-b, r.W+2*b, r.H+2*b, rb, r.Ellipse, fmt.Sprintf("fill:none;stroke:red;stroke-width:%.2f;stroke-dasharray:%.2f", sw, 4*sw))
+		}
+	}
+	return GroupEnd(g)
+}
+
+func roundShape(x, y, w, h, r float64, ellipse bool, s string) string {
+	if ellipse {
+		return fmt.Sprintf(`
+<ellipse cx="%f" cy="%f" rx="%f" ry="%f" style="%s" />`, x+w/2, y+h/2, w/2, h/2, s)
+	}
+	return fmt.Sprintf(`
+<rect x="%f" y="%f" width="%f" height="%f" rx="%f" ry="%f" style="%s" />`, x, y, w, h, r, r, s)
+}
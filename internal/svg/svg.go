@@ -2,6 +2,7 @@ package svg
 
 import (
 	"fmt"
+	"html"
 	"strings"
 )
 
@@ -21,8 +22,14 @@ const (
 )
 
 func Start(w float64, h float64, unit string, plain bool) string {
-	s := fmt.Sprintf(svginitfmt, svgtop, w, unit, h, unit) + " " +
-		fmt.Sprintf(vbfmt, 0.0, 0.0, w, h) + svgns
+	return StartAt(0.0, 0.0, w, h, unit, 1.0, plain)
+}
+
+// StartAt is like Start but the drawing's top left corner is x, y;
+// a drawing unit takes scale units on paper
+func StartAt(x, y, w float64, h float64, unit string, scale float64, plain bool) string {
+	s := fmt.Sprintf(svginitfmt, svgtop, w*scale, unit, h*scale, unit) + " " +
+		fmt.Sprintf(vbfmt, x, y, w, h) + svgns
 	if plain == false {
 		s += svgnsinkscape
 	}
@@ -31,14 +38,19 @@ func Start(w float64, h float64, unit string, plain bool) string {
 }
 
 func StartWeb(w float64, h float64, plain bool) string {
+	return StartWebAt(0.0, 0.0, w, h, plain)
+}
+
+// StartWebAt is like StartWeb but the drawing's top left corner is x, y
+func StartWebAt(x, y, w float64, h float64, plain bool) string {
 	s := svgtop +
 		" style=\"positon:absolute;width:100%;height:100%;\" preserveAspectRatio=\"xMidYMid meet\" " +
-		fmt.Sprintf(vbfmt, 0.0, 0.0, w, h) + svgns
+		fmt.Sprintf(vbfmt, x, y, w, h) + svgns
 	if plain == false {
 		s += svgnsinkscape
 	}
 	s += ">"
-	s += Rect(0.0, 0.0, w, h, "stroke:gray;stroke-width:2;fill:none")
+	s += Rect(x, y, w, h, "stroke:gray;stroke-width:2;fill:none")
 	return s
 }
 
@@ -64,9 +76,14 @@ func Rect(x float64, y float64, w float64, h float64, s string) string {
 <rect x="%f" y="%f" width="%f" height="%f" style="%s" />`, x, y, w, h, s)
 }
 
+func Line(x1, y1, x2, y2 float64, s string) string {
+	return fmt.Sprintf(`
+<line x1="%f" y1="%f" x2="%f" y2="%f" style="%s" />`, x1, y1, x2, y2, s)
+}
+
 func Text(x float64, y float64, transform, txt string, s string) string {
 	return fmt.Sprintf(`
 <text x="%f" y="%f" %s style="%s" >
 %s
-</text>`, x, y, transform, s, txt)
+</text>`, x, y, transform, s, html.EscapeString(txt))
 }
@@ -0,0 +1,121 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/innermond/pak"
+)
+
+// TitleInfo is what gets written into the title block of a sheet
+type TitleInfo struct {
+	Job      string
+	Material string
+	// sheet number, 1 based, out of total sheets
+	Sheet, Sheets int
+	// sheet dimensions
+	W, H float64
+	Unit string
+	// percent of sheet area covered by boxes
+	Utilisation float64
+	Date        string
+	// fills drawn on sheet, as told by Fills; legend lists only these
+	Fills []string
+}
+
+// legend pairs every fill used by Out with its meaning
+var legend = []struct {
+	fill, label string
+}{
+	{fillFirst, "first box"},
+	{fillTop, "top edge"},
+	{fillLeft, "left edge"},
+	{fillOther, "other boxes"},
+	{fillBand, "banded edge"},
+	{fillOverlap, "panel overlap"},
+}
+
+// Fills tells which fills of legend a sheet shows: those Out gives blocks,
+// then banded edges and panel overlaps when drawn
+func Fills(blocks []*pak.Box, top, left float64, banded, overlap bool) []string {
+	used := map[string]bool{fillBand: banded, fillOverlap: overlap}
+	for i, blk := range blocks {
+		if blk != nil {
+			used[blockFill(i, blk, top, left)] = true
+		}
+	}
+	ff := []string{}
+	for _, entry := range legend {
+		if used[entry.fill] {
+			ff = append(ff, entry.fill)
+		}
+	}
+	return ff
+}
+
+// TitleBlockHeight gives the height of a title block that suits a sheet that wide
+func TitleBlockHeight(w float64) float64 {
+	return math.Floor(w/12*100) / 100
+}
+
+// TitleBlock renders a title block of height h under a sheet w wide starting at y;
+// title and legend can be switched on independently
+func TitleBlock(y, w, h float64, ti TitleInfo, title, withLegend, plain bool) string {
+	g := GroupStart("id=\"title\"")
+	if !plain {
+		g = GroupStart("id=\"title\"", "inkscape:label=\"title\"", "inkscape:groupmode=\"layer\"")
+	}
+
+	// border lines are thin relative to the block
+	sw := h / 80
+	g += Rect(0.0, y, w, h, fmt.Sprintf("stroke:#000;stroke-width:%.2f;fill:none", sw))
+
+	// five lines of text, the first one bolder
+	fs := h / 7
+	pad := fs / 2
+	if title {
+		lines := []string{
+			ti.Job,
+			fmt.Sprintf("sheet %d of %d", ti.Sheet, ti.Sheets),
+			"material " + ti.Material,
+			fmt.Sprintf("size %.2fx%.2f %s, utilisation %.2f%%", ti.W, ti.H, ti.Unit, ti.Utilisation),
+			ti.Date,
+		}
+		for i, line := range lines {
+			s := fmt.Sprintf("font-size:%.2fpx;fill:#000", fs)
+			if i == 0 {
+				s += ";font-weight:bold"
+			}
+			g += Text(pad, y+pad+fs*float64(i+1), "", line, s)
+		}
+	}
+
+	if withLegend {
+		// legend takes the right side of the block
+		lx := w * 0.6
+		g += Rect(lx, y, 0.0, h, fmt.Sprintf("stroke:#000;stroke-width:%.2f;fill:none", sw))
+		shown := map[string]bool{}
+		for _, f := range ti.Fills {
+			shown[f] = true
+		}
+		entries := legend[:0:0]
+		for _, entry := range legend {
+			if shown[entry.fill] {
+				entries = append(entries, entry)
+			}
+		}
+		// rows share the block's height when they are too many for their font
+		pitch, ls := fs*1.2, fs
+		if n := float64(len(entries)); n > 0 && pitch*n > h-2*pad {
+			pitch = (h - 2*pad) / n
+			ls = pitch / 1.2
+		}
+		for i, entry := range entries {
+			ly := y + pad + pitch*float64(i)
+			g += Rect(lx+pad, ly, ls, ls, entry.fill+fmt.Sprintf(";stroke:#000;stroke-width:%.2f", sw))
+			g += Text(lx+2*pad+ls, ly+ls*0.85, "", entry.label, fmt.Sprintf("font-size:%.2fpx;fill:#000", ls))
+		}
+	}
+
+	return GroupEnd(g)
+}
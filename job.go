@@ -0,0 +1,128 @@
+package packong
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Job holds everything a packing run needs: sheet, pieces, prices and options;
+// the api receives it as json, the cli reads it from a json or yaml job file
+type Job struct {
+	// dimensions's boxes
+	Dimensions []string `json:"dimensions" yaml:"dimensions"`
+	// filename of a graphic file (svg) with boxes packed
+	Outname string `json:"outname" yaml:"outname"`
+	// measurement unit: mm, cm
+	Unit string `json:"unit" yaml:"unit"`
+	// mother box dimensions
+	Width  float64 `json:"width" yaml:"width"`
+	Height float64 `json:"height" yaml:"height"`
+	// when true boxes area is surround exactly area boxes swarm
+	Tight bool `json:"tight" yaml:"tight"`
+	// plain FALSE indicates svg output is as-inkscape
+	Plain bool `json:"plain" yaml:"plain"`
+	// will rendered "wxh" dimensions pair on every box
+	ShowDim bool `json:"showdim" yaml:"showdim"`
+	// label sheet grid; labels are made when both are set
+	LabelCols int `json:"label_cols" yaml:"label_cols"`
+	LabelRows int `json:"label_rows" yaml:"label_rows"`
+	// font size bounds of dimensions in units; zero means default
+	FontMin float64 `json:"font_min" yaml:"font_min"`
+	FontMax float64 `json:"font_max" yaml:"font_max"`
+	// title block with job name and material under every sheet
+	Title    string `json:"title" yaml:"title"`
+	Material string `json:"material" yaml:"material"`
+	// legend explaining colours of boxes
+	Legend bool `json:"legend" yaml:"legend"`
+	// amount of expanding area's box in order to accomodate to loosing material
+	// when a physical cut (that has real width which eats from box area) occurs
+	Cutwidth float64 `json:"cutwidth" yaml:"cutwidth"`
+	// gap kept between pieces, apart from cut width
+	Gutter float64 `json:"gutter" yaml:"gutter"`
+	// point from where boxes are lay down
+	Topleftmargin float64 `json:"topleftmargin" yaml:"topleftmargin"`
+	// margins of every side; when set they replace topleftmargin
+	Margins Margins `json:"margins" yaml:"margins"`
+	// how much pieces extend past their trim edges
+	Bleed float64 `json:"bleed" yaml:"bleed"`
+
+	// prices:
+	// mu - material used, a price that reflects man's work
+	// ml - material lost, a price regarding raw material - that's it it doesn't contains man's work
+	// pp - perimeter price, a price connected with number of cuts needed for breaking big sheet to needed pieces
+	// pd - move on the spot price
+	Mu float64 `json:"mu" yaml:"mu"`
+	Ml float64 `json:"ml" yaml:"ml"`
+	Pp float64 `json:"pp" yaml:"pp"`
+	Pd float64 `json:"pd" yaml:"pd"`
+	// customer group looked up for discounts in server's price list
+	Group string `json:"group" yaml:"group"`
+	// pb - banding price per linear meter of edge tape
+	Pb float64 `json:"pb" yaml:"pb"`
+	// ph - labour cost per square meter of used material
+	Ph float64 `json:"ph" yaml:"ph"`
+	// price follows from costs keeping this margin percent
+	TargetMargin float64 `json:"target_margin" yaml:"target_margin"`
+	// price set by hand; margin follows from costs
+	ManualPrice float64 `json:"manual_price" yaml:"manual_price"`
+	// currency of answered prices, looked up in server's rates; empty means base currency
+	Currency string `json:"currency" yaml:"currency"`
+	// value added tax percent
+	Vat float64 `json:"vat" yaml:"vat"`
+	// rounding of price lines and totals: halfup (default), halfeven, down, up
+	RoundLine  string `json:"round_line" yaml:"round_line"`
+	RoundTotal string `json:"round_total" yaml:"round_total"`
+	// totals are rounded to a multiple of it
+	RoundStep Money `json:"round_step" yaml:"round_step"`
+
+	// pieces too large for sheet are split into panels overlapping that much
+	Tile    bool    `json:"tile" yaml:"tile"`
+	Overlap float64 `json:"overlap" yaml:"overlap"`
+
+	// pieces made of uploaded artwork print this many times its size; zero keeps it
+	ArtScale float64 `json:"art_scale" yaml:"art_scale"`
+	// artwork of pieces is placed into sheets: embed, link or empty for none
+	Impose string `json:"impose" yaml:"impose"`
+	// crop marks at piece corners and registration marks on sheets
+	Marks Marks `json:"marks" yaml:"marks"`
+
+	// sheets filled with as many pieces as fit, quantities being ratios;
+	// zero packs quantities asked for
+	HowMany int `json:"how_many" yaml:"how_many"`
+
+	// shape entries nested by their outlines instead of packing dimensions
+	Shapes []string `json:"shapes" yaml:"shapes"`
+	// rotation step in degrees of nested shapes and gap kept between their outlines
+	Step    float64 `json:"step" yaml:"step"`
+	Spacing float64 `json:"spacing" yaml:"spacing"`
+
+	// it considers lost material as valuable as used material
+	Greedy bool `json:"greedy" yaml:"greedy"`
+	// vendors are selling lengths of sheets measured by natural numbers
+	Vendorsellint bool `json:"vendorsellint" yaml:"vendorsellint"`
+}
+
+// DecodeJob reads a job file over j, so fields the file lacks keep their values;
+// name's extension tells json from yaml, either failing on fields a job has not
+func DecodeJob(name string, r io.Reader, j *Job) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.DisallowUnknownFields()
+		return d.Decode(j)
+	case ".yaml", ".yml":
+		return yaml.UnmarshalStrict(b, j)
+	}
+	return fmt.Errorf("job %q is neither json nor yaml", name)
+}
@@ -0,0 +1,36 @@
+package packong
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeJob(t *testing.T) {
+	in := "width: 1270\ndimensions: [500x1200x2, 780x650]\nround_step: 0.05\n"
+	for name, in := range map[string]string{
+		"job.yaml": in,
+		"job.json": `{"width": 1270, "dimensions": ["500x1200x2", "780x650"], "round_step": 0.05}`,
+	} {
+		j := Job{Height: 50000, Mu: 15}
+		if err := DecodeJob(name, strings.NewReader(in), &j); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if j.Width != 1270 || len(j.Dimensions) != 2 || j.RoundStep.String() != "0.05" {
+			t.Errorf("%s: got %+v", name, j)
+		}
+		// missing fields keep their values
+		if j.Height != 50000 || j.Mu != 15 {
+			t.Errorf("%s: got height %v and mu %v, expected them kept", name, j.Height, j.Mu)
+		}
+	}
+
+	// misspelled fields are not let by
+	for name, in := range map[string]string{
+		"job.yaml": "widht: 1270\n",
+		"job.json": `{"widht": 1270}`,
+	} {
+		if err := DecodeJob(name, strings.NewReader(in), &Job{}); err == nil {
+			t.Errorf("%s: expected error for unknown field", name)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package packong
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/innermond/packong/internal/barcode"
+	"github.com/innermond/packong/internal/pdf"
+	"github.com/innermond/packong/internal/svg"
+)
+
+// LabelSheet describes a sheet of sticky labels laid in a grid; sizes are in millimeters
+type LabelSheet struct {
+	PageW, PageH float64
+	Cols, Rows   int
+	// Margin is page's margin, Gap is space between labels
+	Margin, Gap float64
+}
+
+// NewLabelSheet gives an A4 label sheet with cols x rows labels
+func NewLabelSheet(cols, rows int) LabelSheet {
+	return LabelSheet{PageW: 210, PageH: 297, Cols: cols, Rows: rows, Margin: 5, Gap: 2}
+}
+
+func (ls LabelSheet) labelSize() (float64, float64) {
+	w := (ls.PageW - 2*ls.Margin - float64(ls.Cols-1)*ls.Gap) / float64(ls.Cols)
+	h := (ls.PageH - 2*ls.Margin - float64(ls.Rows-1)*ls.Gap) / float64(ls.Rows)
+	return w, h
+}
+
+func (ls LabelSheet) valid() error {
+	if ls.Cols < 1 || ls.Rows < 1 {
+		return errors.New("label sheet needs at least a row and a column")
+	}
+	if w, h := ls.labelSize(); w <= 0 || h <= 0 {
+		return errors.New("labels do not fit on page")
+	}
+	return nil
+}
+
+// labelDrawer is a page of labels under construction
+type labelDrawer interface {
+	page()
+	rect(x, y, w, h float64, fill bool)
+	text(x, y, size float64, txt string) error
+}
+
+type svgLabels struct {
+	pages []string
+}
+
+func (d *svgLabels) page() {
+	d.pages = append(d.pages, "")
+}
+
+func (d *svgLabels) rect(x, y, w, h float64, fill bool) {
+	s := "stroke:#999;stroke-width:0.2;fill:none"
+	if fill {
+		s = "fill:#000;stroke:none"
+	}
+	d.pages[len(d.pages)-1] += svg.Rect(x, y, w, h, s)
+}
+
+func (d *svgLabels) text(x, y, size float64, txt string) error {
+	d.pages[len(d.pages)-1] += svg.Text(x, y, "", txt, fmt.Sprintf("font-family:Helvetica,Arial,sans-serif;font-size:%.2fpx;fill:#000", size))
+	return nil
+}
+
+type pdfLabels struct {
+	w, h float64
+	doc  pdf.Doc
+}
+
+func (d *pdfLabels) page() {
+	d.doc.AddPage(d.w, d.h)
+}
+
+func (d *pdfLabels) rect(x, y, w, h float64, fill bool) {
+	d.doc.Rect(x, y, w, h, fill)
+}
+
+func (d *pdfLabels) text(x, y, size float64, txt string) error {
+	return d.doc.Text(x, y, size, txt)
+}
+
+// fit shrinks txt's size down to a half so it is no wider than w,
+// then cuts it short with an ellipsis
+func fit(txt string, size, w float64) (string, float64) {
+	tw := pdf.TextWidth(txt, size)
+	if tw <= w {
+		return txt, size
+	}
+	if size*w/tw >= size/2 {
+		return txt, size * w / tw
+	}
+	size /= 2
+	rr := []rune(txt)
+	for len(rr) > 0 && pdf.TextWidth(string(rr)+"…", size) > w {
+		rr = rr[:len(rr)-1]
+	}
+	return string(rr) + "…", size
+}
+
+// drawLabels puts a label for every placed piece on as many pages as needed
+func (op *Op) drawLabels(d labelDrawer, rep *Report, ls LabelSheet) error {
+	if err := ls.valid(); err != nil {
+		return err
+	}
+	lw, lh := ls.labelSize()
+	perPage := ls.Cols * ls.Rows
+	pad := lh * 0.06
+	fs := lh / 9
+
+	n := 0
+	for _, pl := range rep.Layout {
+		if pl.Piece == nil {
+			continue
+		}
+		if n%perPage == 0 {
+			d.page()
+		}
+		col, row := n%ls.Cols, (n%perPage)/ls.Cols
+		x := ls.Margin + float64(col)*(lw+ls.Gap)
+		y := ls.Margin + float64(row)*(lh+ls.Gap)
+		n++
+
+		// cutting outline of label
+		d.rect(x, y, lw, lh, false)
+
+		dims := fmt.Sprintf("%.2fx%.2f %s", pl.Piece.W, pl.Piece.H, op.unit)
+		lines := []string{pl.Piece.Label, dims, fmt.Sprintf("sheet %d", pl.Sheet), op.job}
+		for i, line := range lines {
+			line, size := fit(line, fs, lw-2*pad)
+			if err := d.text(x+pad, y+pad+fs*1.2*float64(i+1), size, line); err != nil {
+				return err
+			}
+		}
+
+		id := strconv.Itoa(pl.Piece.ID)
+		widths, err := barcode.Code128(id)
+		if err != nil {
+			return err
+		}
+		bw := lw - 2*pad
+		module := bw / float64(barcode.Modules(widths))
+		bh := lh * 0.3
+		by := y + lh - pad - bh
+		bx := x + pad + barcode.QuietZone*module
+		for i, w := range widths {
+			// even positions are bars, odd ones are spaces
+			if i%2 == 0 {
+				d.rect(bx, by, float64(w)*module, bh, true)
+			}
+			bx += float64(w) * module
+		}
+	}
+
+	if n == 0 {
+		return errors.New("no placed pieces to label")
+	}
+	return nil
+}
+
+func (op *Op) labelsName() string {
+	if op.outname == "" {
+		return "labels"
+	}
+	return op.outname + ".labels"
+}
+
+// Labels lays a printable label for every placed piece of rep;
+// it gives a svg for every page of ls
+func (op *Op) Labels(rep *Report, ls LabelSheet) ([]FitReader, error) {
+	d := &svgLabels{}
+	if err := op.drawLabels(d, rep, ls); err != nil {
+		return nil, err
+	}
+
+	outs := []FitReader{}
+	for i, page := range d.pages {
+		fn := fmt.Sprintf("%s.%d.svg", op.labelsName(), i+1)
+		s := svg.Start(ls.PageW, ls.PageH, "mm", true) + svg.End(page)
+		outs = append(outs, FitReader{fn: strings.NewReader(s)})
+	}
+	return outs, nil
+}
+
+// LabelsPDF is like Labels but all pages go into a single pdf
+func (op *Op) LabelsPDF(rep *Report, ls LabelSheet) (FitReader, error) {
+	d := &pdfLabels{w: ls.PageW, h: ls.PageH}
+	if err := op.drawLabels(d, rep, ls); err != nil {
+		return nil, err
+	}
+	return FitReader{op.labelsName() + ".pdf": bytes.NewReader(d.doc.Bytes())}, nil
+}
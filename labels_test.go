@@ -0,0 +1,105 @@
+package packong
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/innermond/packong/internal/pdf"
+)
+
+// labelsDrawn records what drawLabels draws
+type labelsDrawn struct {
+	pages int
+	cells [][3]float64
+	texts []drawnText
+}
+
+type drawnText struct {
+	size float64
+	txt  string
+}
+
+func (d *labelsDrawn) page() {
+	d.pages++
+}
+
+func (d *labelsDrawn) rect(x, y, w, h float64, fill bool) {
+	if !fill {
+		d.cells = append(d.cells, [3]float64{float64(d.pages), x, y})
+	}
+}
+
+func (d *labelsDrawn) text(x, y, size float64, txt string) error {
+	d.texts = append(d.texts, drawnText{size, txt})
+	return nil
+}
+
+func labelled(labels ...string) *Report {
+	rep := &Report{}
+	for i, l := range labels {
+		rep.Layout = append(rep.Layout, Placement{Piece: &Piece{ID: i + 1, Label: l, W: 500, H: 300}, Sheet: 1})
+	}
+	return rep
+}
+
+func TestLabels(t *testing.T) {
+	op := NewOp(1000, 2000, nil, "mm")
+	ls := LabelSheet{PageW: 100, PageH: 100, Cols: 2, Rows: 2, Margin: 5, Gap: 2}
+	long := strings.Repeat("cabinet door ", 20)
+
+	d := &labelsDrawn{}
+	if err := op.drawLabels(d, labelled("a", "b", "c", "d", long), ls); err != nil {
+		t.Fatal(err)
+	}
+	// labels 44 wide and high, left to right then down, a page at a time
+	want := [][3]float64{{1, 5, 5}, {1, 51, 5}, {1, 5, 51}, {1, 51, 51}, {2, 5, 5}}
+	if d.pages != 2 || len(d.cells) != len(want) {
+		t.Fatalf("got %d labels on %d pages", len(d.cells), d.pages)
+	}
+	for i, c := range d.cells {
+		if c != want[i] {
+			t.Errorf("label %d: got page, x, y %v, expected %v", i+1, c, want[i])
+		}
+	}
+	// long text fits the label, shrunk or cut short
+	lw, lh := ls.labelSize()
+	for _, tx := range d.texts {
+		if w := pdf.TextWidth(tx.txt, tx.size); w > lw-2*lh*0.06+1e-9 {
+			t.Errorf("%q is %v wide, more than label", tx.txt, w)
+		}
+	}
+	if last := d.texts[len(d.texts)-4]; !strings.HasSuffix(last.txt, "…") {
+		t.Errorf("got %q, expected long label cut short", last.txt)
+	}
+
+	if err := op.drawLabels(d, &Report{}, ls); err == nil {
+		t.Error("expected error when nothing is placed")
+	}
+	if err := op.drawLabels(d, labelled("a"), LabelSheet{PageW: 10, PageH: 10, Cols: 4, Rows: 4, Margin: 5}); err == nil {
+		t.Error("expected error when labels do not fit on page")
+	}
+}
+
+func TestLabelsPDF(t *testing.T) {
+	op := NewOp(1000, 2000, nil, "mm")
+	out, err := op.LabelsPDF(labelled("Ușă", "b", "c"), LabelSheet{PageW: 100, PageH: 100, Cols: 1, Rows: 2, Margin: 5, Gap: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(out["labels.pdf"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ușă goes as codes of font's encoding
+	for _, want := range []string{"%PDF-1.4", "/Count 2", `(U\004\002) Tj`} {
+		if !bytes.Contains(b, []byte(want)) {
+			t.Errorf("expected %s in pdf", want)
+		}
+	}
+
+	if _, err := op.LabelsPDF(labelled("日本"), NewLabelSheet(3, 8)); err == nil {
+		t.Error("expected error for a label Helvetica can't show")
+	}
+}
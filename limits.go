@@ -0,0 +1,87 @@
+package packong
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Limits bound how big a job may grow; a zero field means no limit
+type Limits struct {
+	// pieces of a single dimension entry
+	MaxQty int
+	// pieces of all entries
+	MaxPieces int
+	// sheets a layout may take
+	MaxSheets int
+	// arrangements times strategies Fit evaluates
+	MaxCandidates int
+	// big box size, in units
+	MaxWidth, MaxHeight float64
+}
+
+// DefaultLimits are limits of a new Op
+var DefaultLimits = Limits{MaxQty: 50, MaxPieces: 500}
+
+// ParseLimits reads limits such as "qty=100,pieces=500,sheets=20";
+// limits left out keep their defaults
+func ParseLimits(s string) (Limits, error) {
+	l := DefaultLimits
+	if strings.TrimSpace(s) == "" {
+		return l, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return l, fmt.Errorf("limit %q needs name=value", kv)
+		}
+		name := strings.TrimSpace(parts[0])
+		v, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || v < 0 {
+			return l, fmt.Errorf("limit %s needs a value not below zero; received %q", name, parts[1])
+		}
+		switch name {
+		case "qty":
+			l.MaxQty = int(v)
+		case "pieces":
+			l.MaxPieces = int(v)
+		case "sheets":
+			l.MaxSheets = int(v)
+		case "candidates":
+			l.MaxCandidates = int(v)
+		case "width":
+			l.MaxWidth = v
+		case "height":
+			l.MaxHeight = v
+		default:
+			return l, fmt.Errorf("unknown limit %q", name)
+		}
+	}
+	return l, nil
+}
+
+// LimitError tells a job went over one of its limits
+type LimitError struct {
+	// Limit is one of qty, pieces, sheets, candidates, width, height
+	Limit string
+	Max   float64
+	Got   float64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s over limit; %v allowed, received %v", e.Limit, e.Max, e.Got)
+}
+
+// Limits replaces op's limits
+func (op *Op) Limits(l Limits) *Op {
+	op.limits = l
+	return op
+}
+
+// over tells got goes over max, when max is a limit
+func over(limit string, max, got float64) error {
+	if max > 0 && got > max {
+		return &LimitError{limit, max, got}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package packong
+
+import (
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestLimits(t *testing.T) {
+	type test struct {
+		limits string
+		dd     []string
+		want   string
+	}
+	tt := []test{
+		{"", []string{"100x100x51"}, "qty"},
+		{"qty=0,pieces=10", []string{"100x100x6", "50x50x5"}, "pieces"},
+		{"sheets=2", []string{"400x400x5"}, "sheets"},
+		{"width=400", []string{"100x100"}, "width"},
+		{"qty=100,sheets=3", []string{"100x100x60"}, ""},
+	}
+	for _, tc := range tt {
+		l, err := ParseLimits(tc.limits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		op := NewOp(500, 500, tc.dd, "mm").Limits(l)
+		boxes, err := op.BoxesFromString()
+		if err == nil {
+			_, _, err = op.Fit([][]*pak.Box{boxes}, false)
+		}
+		got := ""
+		if le, ok := err.(*LimitError); ok {
+			got = le.Limit
+		} else if err != nil {
+			t.Errorf("%s: %v", tc.limits, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got limit %q, expected %q", tc.limits, got, tc.want)
+		}
+	}
+}
+
+func TestLimitsAfterValidation(t *testing.T) {
+	// a qty over limit keeps other bad entries from being told
+	op := NewOp(500, 500, []string{"100x100x51", "qwqw", "50x"}, "mm")
+	_, err := op.BoxesFromString()
+	if ve, ok := err.(ValidationError); !ok || len(ve) != 2 {
+		t.Errorf("got %v, expected both bad entries", err)
+	}
+
+	// pieces are bounded by default, and not made past limit
+	dd := []string{}
+	for i := 0; i < 11; i++ {
+		dd = append(dd, "10x10x50")
+	}
+	op = NewOp(500, 500, dd, "mm")
+	_, err = op.BoxesFromString()
+	if le, ok := err.(*LimitError); !ok || le.Limit != "pieces" {
+		t.Errorf("got %v, expected pieces limit", err)
+	}
+	if len(op.pieces) > DefaultLimits.MaxPieces {
+		t.Errorf("got %d pieces made, expected no more than limit", len(op.pieces))
+	}
+
+	op = NewOp(500, 500, nil, "mm").Limits(Limits{MaxCandidates: 100})
+	if err := op.CheckCandidates(NumPermutations(5)); err == nil {
+		t.Error("expected 120 permutations by every strategy over limit")
+	}
+	if err := op.CheckCandidates(NumPermutations(2)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNestLimits(t *testing.T) {
+	square, _ := ParseShape(`poly="0,0 200,0 200,200 0,200"`)
+	bar, _ := ParseShape(`poly="0,0 300,0 300,5 0,5" rotate=no`)
+	// a bar fitting no sheet takes no sheet over limit
+	l, _ := ParseLimits("sheets=1")
+	rep, _, err := NewOp(220, 1000, nil, "mm").Limits(l).Nest([]Shape{square, bar}, Nesting{Resolution: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.NumSheetUsed != 1 || rep.UnfitLen != 1 {
+		t.Errorf("got %v sheets and %d unfit, expected 1 of each", rep.NumSheetUsed, rep.UnfitLen)
+	}
+
+	for limits, want := range map[string]string{"qty=2": "qty", "qty=5,pieces=4": "pieces"} {
+		l, _ := ParseLimits(limits)
+		square.Qty = 3
+		bar.Qty = 2
+		_, _, err := NewOp(220, 1000, nil, "mm").Limits(l).Nest([]Shape{square, bar}, Nesting{Resolution: 1})
+		if le, ok := err.(*LimitError); !ok || le.Limit != want {
+			t.Errorf("%s: got %v, expected %s limit", limits, err, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package packong
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Margins keep sheet edges free of pieces, like a gripper edge a printer can't print on
+type Margins struct {
+	Top    float64 `json:"top" yaml:"top"`
+	Right  float64 `json:"right" yaml:"right"`
+	Bottom float64 `json:"bottom" yaml:"bottom"`
+	Left   float64 `json:"left" yaml:"left"`
+}
+
+// ParseMargins reads margins written like css does: "all", "top-bottom,right-left",
+// "top,right-left,bottom" or "top,right,bottom,left"
+func ParseMargins(s string) (Margins, error) {
+	var m Margins
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+	vv := []float64{}
+	for _, p := range strings.Split(s, ",") {
+		v, err := parseLength(p)
+		if err != nil || v < 0 {
+			return m, fmt.Errorf("margin %q must be a length not below zero", p)
+		}
+		vv = append(vv, v)
+	}
+	switch len(vv) {
+	case 1:
+		m = Margins{vv[0], vv[0], vv[0], vv[0]}
+	case 2:
+		m = Margins{vv[0], vv[1], vv[0], vv[1]}
+	case 3:
+		m = Margins{vv[0], vv[1], vv[2], vv[1]}
+	case 4:
+		m = Margins{vv[0], vv[1], vv[2], vv[3]}
+	default:
+		return m, fmt.Errorf("margins %q need at most four lengths", s)
+	}
+	return m, nil
+}
+
+func (m Margins) String() string {
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strings.Join([]string{f(m.Top), f(m.Right), f(m.Bottom), f(m.Left)}, ",")
+}
+
+// Margins sets margins of every side of sheet. Sheets are bought with their margins,
+// so used and vendored length hold top and bottom margins; before margins were
+// per side, the top left margin was left out of them
+func (op *Op) Margins(m Margins) *Op {
+	op.margins = m
+	return op
+}
+
+// Bleed sets how much pieces extend past their trim edges on every side;
+// a piece's own bleed wins
+func (op *Op) Bleed(b float64) *Op {
+	op.bleed = b
+	return op
+}
+
+// room gives width and height of sheet left inside margins
+func (op *Op) room() (float64, float64) {
+	return op.width - op.margins.Left - op.margins.Right, op.height - op.margins.Top - op.margins.Bottom
+}
+
+// bleedBoxes gives bleed and trim boxes, as x, y, w, h, of pieces having bleed
+func (op *Op) bleedBoxes(sh sheet) (bleed, trim [][4]float64) {
+	for _, box := range sh.boxes {
+		pc := sh.pieces[box]
+		// outlines other than rectangles draw their own bleed
+		if pc == nil || pc.Bleed == 0 || pc.Form != FormRect || pc.Radius > 0 {
+			continue
+		}
+		// cut width is not part of the piece
+		x, y := box.X, box.Y
+		w, h := box.W-0.5*op.cutwidth, box.H-0.5*op.cutwidth
+		b := pc.Bleed
+		bleed = append(bleed, [4]float64{x, y, w, h})
+		trim = append(trim, [4]float64{x + b, y + b, w - 2*b, h - 2*b})
+	}
+	return
+}
@@ -0,0 +1,91 @@
+package packong
+
+import (
+	"math"
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestMargins(t *testing.T) {
+	m, err := ParseMargins("20,10,10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != (Margins{20, 10, 10, 10}) {
+		t.Fatalf("got %+v", m)
+	}
+
+	op := NewOp(1000, 2000, []string{"300x200x6 bleed=3"}, "mm").Margins(m)
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, _, err := op.Fit([][]*pak.Box{boxes}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Layout) != 6 {
+		t.Fatalf("got %d pieces placed, expected 6", len(rep.Layout))
+	}
+	for _, pl := range rep.Layout {
+		if pl.X < m.Left || pl.Y < m.Top || pl.X+pl.W > 1000-m.Right {
+			t.Errorf("piece at %v,%v %vx%v crosses margins", pl.X, pl.Y, pl.W, pl.H)
+		}
+		if pl.W*pl.H != 306*206 {
+			t.Errorf("got %vx%v, expected bleed around 300x200", pl.W, pl.H)
+		}
+	}
+	// 3 per row and 2 rows between top and bottom margins
+	if want := (20 + 2*206 + 10) / 1000.0; rep.VendoredLength != 1 || rep.UsedArea != want {
+		t.Errorf("got used area %v, expected %v", rep.UsedArea, want)
+	}
+	// bleed is cut away, so only trim is used material
+	if want := 6 * 0.3 * 0.2; math.Abs(rep.BoxesArea-want) > 1e-9 {
+		t.Errorf("got boxes area %v, expected %v", rep.BoxesArea, want)
+	}
+}
+
+func TestGutter(t *testing.T) {
+	op := NewOp(1000, 1000, []string{"490x100x2x0"}, "mm").Gutter(20)
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, _, err := op.Fit([][]*pak.Box{boxes}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Layout) != 2 {
+		t.Fatalf("got %d pieces placed, expected 2", len(rep.Layout))
+	}
+	a, b := rep.Layout[0], rep.Layout[1]
+	if a.W != 490 || a.H != 100 || b.W != 490 {
+		t.Errorf("got %vx%v, expected pieces keep their size", a.W, a.H)
+	}
+	// side by side with a gutter between, or one under the other
+	if gap := math.Max(math.Abs(a.X-b.X)-490, math.Abs(a.Y-b.Y)-100); gap != 20 {
+		t.Errorf("got gap %v between pieces, expected 20", gap)
+	}
+	if rep.BoxesArea != 2*0.49*0.1 {
+		t.Errorf("got boxes area %v, expected gutter left out", rep.BoxesArea)
+	}
+}
+
+func TestMarginsUnfit(t *testing.T) {
+	for _, pieces := range [][]string{{"300x200"}, {"300x200", "3000x3000 rotate=no"}} {
+		op := NewOp(1000, 2000, pieces, "mm").Margins(Margins{10, 10, 10, 10})
+		boxes, err := op.BoxesFromString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep, _, err := op.Fit([][]*pak.Box{boxes}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a pass placing nothing must not bill margins as another sheet
+		if rep.NumSheetUsed != 1 || rep.VendoredLength != 1 {
+			t.Errorf("%v: got %v sheets of %vm, expected 1 of 1m", pieces, rep.NumSheetUsed, rep.VendoredLength)
+		}
+	}
+}
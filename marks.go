@@ -0,0 +1,230 @@
+package packong
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/innermond/packong/internal/svg"
+	"github.com/innermond/pak"
+)
+
+// shapes of registration marks
+const (
+	RegSquare = "square"
+	RegCircle = "circle"
+)
+
+// Marks guide cutting of printed sheets: crop marks at piece corners lead a guillotine,
+// registration marks on sheet lead print and cut plotters; room they need is kept
+// by growing margins and gutter
+type Marks struct {
+	// crop marks are Crop long and start Offset away from trim, or past bleed when larger;
+	// zero Crop draws none
+	Crop   float64 `json:"crop" yaml:"crop"`
+	Offset float64 `json:"offset" yaml:"offset"`
+	// registration marks are squares or circles Size big, Inset from sheet edges,
+	// at sheet corners and along sides at most Every apart; zero Size draws none
+	Reg   string  `json:"reg" yaml:"reg"`
+	Size  float64 `json:"size" yaml:"size"`
+	Inset float64 `json:"inset" yaml:"inset"`
+	Every float64 `json:"every" yaml:"every"`
+}
+
+// ParseMarks reads marks such as "crop=5,offset=2,reg=circle,size=5,inset=10,every=500"
+func ParseMarks(s string) (Marks, error) {
+	m := Marks{Reg: RegSquare}
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return m, fmt.Errorf("mark %q needs name=value", kv)
+		}
+		name, v := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if name == "reg" {
+			if v != RegSquare && v != RegCircle {
+				return m, fmt.Errorf("registration mark %q is neither square nor circle", v)
+			}
+			m.Reg = v
+			continue
+		}
+		f, err := parseLength(v)
+		if err != nil || f < 0 {
+			return m, fmt.Errorf("mark %s needs a length not below zero; received %q", name, v)
+		}
+		switch name {
+		case "crop":
+			m.Crop = f
+		case "offset":
+			m.Offset = f
+		case "size":
+			m.Size = f
+		case "inset":
+			m.Inset = f
+		case "every":
+			m.Every = f
+		default:
+			return m, fmt.Errorf("unknown mark %q", name)
+		}
+	}
+	return m, nil
+}
+
+// RegMark is a registration mark centred at X, Y on a sheet
+type RegMark struct {
+	Sheet int     `json:"sheet"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Size  float64 `json:"size"`
+	Shape string  `json:"shape"`
+}
+
+// Marks adds crop and registration marks to sheets
+func (op *Op) Marks(m Marks) *Op {
+	op.marks = m
+	return op
+}
+
+// reserve grows margins and gutter so marks lay clear of pieces
+func (op *Op) reserve() {
+	grow := func(room float64) {
+		op.margins.Top = math.Max(op.margins.Top, room)
+		op.margins.Right = math.Max(op.margins.Right, room)
+		op.margins.Bottom = math.Max(op.margins.Bottom, room)
+		op.margins.Left = math.Max(op.margins.Left, room)
+	}
+	if c := op.marks.Crop; c > 0 {
+		// neighbours' marks share gutter
+		reach := op.marks.Offset + c
+		op.gutter = math.Max(op.gutter, 2*reach)
+		grow(reach)
+	}
+	if s := op.marks.Size; s > 0 {
+		// as much clear room as mark's size keeps plotter's sensor off print
+		grow(op.marks.Inset + 2*s)
+	}
+}
+
+// cropMarks gives lines, as x1, y1, x2, y2, prolonging trim edges past every piece corner
+func (op *Op) cropMarks(sh sheet) [][4]float64 {
+	lines := [][4]float64{}
+	if op.marks.Crop <= 0 {
+		return lines
+	}
+	for _, box := range sh.boxes {
+		for _, pl := range placements(sh.pieces[box], box, 0) {
+			pc := pl.Piece
+			if pc == nil {
+				continue
+			}
+			w, h := pc.W, pc.H
+			if pl.Rotated {
+				w, h = h, w
+			}
+			x1, y1 := pl.X+pc.Bleed, pl.Y+pc.Bleed
+			x2, y2 := x1+w, y1+h
+			from := math.Max(op.marks.Offset, pc.Bleed)
+			to := from + op.marks.Crop
+			for _, y := range []float64{y1, y2} {
+				lines = append(lines, [4]float64{x1 - from, y, x1 - to, y}, [4]float64{x2 + from, y, x2 + to, y})
+			}
+			for _, x := range []float64{x1, x2} {
+				lines = append(lines, [4]float64{x, y1 - from, x, y1 - to}, [4]float64{x, y2 + from, x, y2 + to})
+			}
+		}
+	}
+	return lines
+}
+
+// regMarks places registration marks on a sheet l long: at its corners, then along
+// both sides evenly so no two are more than Every apart
+func (op *Op) regMarks(sheet int, l float64) []RegMark {
+	m := op.marks
+	if m.Size <= 0 {
+		return nil
+	}
+	c := m.Inset + m.Size/2
+	left, right := c, op.width-c
+	top, bottom := c, l-c
+	ys := []float64{top}
+	if span := bottom - top; m.Every > 0 && span > m.Every {
+		n := int(math.Ceil(span / m.Every))
+		for i := 1; i < n; i++ {
+			ys = append(ys, top+span*float64(i)/float64(n))
+		}
+	}
+	ys = append(ys, bottom)
+	shape := m.Reg
+	if shape == "" {
+		shape = RegSquare
+	}
+	rr := []RegMark{}
+	for _, y := range ys {
+		for _, x := range []float64{left, right} {
+			rr = append(rr, RegMark{Sheet: sheet, X: x, Y: y, Size: m.Size, Shape: shape})
+		}
+	}
+	return rr
+}
+
+// printedLength tells how long a sheet is printed: down to its lowest box and bottom margin
+func (op *Op) printedLength(boxes []*pak.Box) float64 {
+	if !op.tight {
+		return op.height
+	}
+	l := op.margins.Top
+	for _, b := range boxes {
+		l = math.Max(l, b.Y+b.H)
+	}
+	return l + op.margins.Bottom
+}
+
+// registration gives registration marks of every sheet layout uses
+func (op *Op) registration(layout []Placement) []RegMark {
+	if op.marks.Size <= 0 {
+		return nil
+	}
+	boxes := map[int][]*pak.Box{}
+	sheets := 0
+	for _, pl := range layout {
+		boxes[pl.Sheet] = append(boxes[pl.Sheet], &pak.Box{X: pl.X, Y: pl.Y, W: pl.W, H: pl.H})
+		if pl.Sheet > sheets {
+			sheets = pl.Sheet
+		}
+	}
+	rr := []RegMark{}
+	for s := 1; s <= sheets; s++ {
+		rr = append(rr, op.regMarks(s, op.printedLength(boxes[s]))...)
+	}
+	return rr
+}
+
+// svgMarks turns registration marks into what svg draws
+func svgMarks(rr []RegMark) []svg.Mark {
+	mm := []svg.Mark{}
+	for _, r := range rr {
+		mm = append(mm, svg.Mark{X: r.X, Y: r.Y, Size: r.Size, Circle: r.Shape == RegCircle})
+	}
+	return mm
+}
+
+// String writes marks as ParseMarks reads them
+func (m Marks) String() string {
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	ss := []string{}
+	if m.Crop > 0 {
+		ss = append(ss, "crop="+f(m.Crop), "offset="+f(m.Offset))
+	}
+	if m.Size > 0 {
+		if m.Reg != "" {
+			ss = append(ss, "reg="+m.Reg)
+		}
+		ss = append(ss, "size="+f(m.Size), "inset="+f(m.Inset), "every="+f(m.Every))
+	}
+	return strings.Join(ss, ",")
+}
@@ -0,0 +1,73 @@
+package packong
+
+import (
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestMarks(t *testing.T) {
+	m, err := ParseMarks("crop=4,offset=2,reg=circle,size=5,inset=5,every=200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := ParseMarks(m.String()); err != nil || again != m {
+		t.Errorf("got %+v from %q, expected %+v", again, m.String(), m)
+	}
+	for _, s := range []string{"crop=-1", "reg=star,size=5", "ink=2"} {
+		if _, err := ParseMarks(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+
+	op := NewOp(600, 5000, []string{"100x60x12"}, "mm").VendorSellInt(false).Marks(m)
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// registration marks and their clearance take more than crop marks
+	if op.margins != (Margins{15, 15, 15, 15}) || op.gutter != 12 {
+		t.Errorf("got margins %v gutter %v, expected 15 and 12", op.margins, op.gutter)
+	}
+	rep, _, err := op.Fit([][]*pak.Box{boxes}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, a := range rep.Layout {
+		for _, b := range rep.Layout[i+1:] {
+			if d := gap(a, b); a.Sheet == b.Sheet && d < 12-1e-9 {
+				t.Errorf("pieces at %v,%v and %v,%v are %v apart, crop marks need 12", a.X, a.Y, b.X, b.Y, d)
+			}
+		}
+	}
+
+	// corners, then along sides no more than 200 apart
+	rr := rep.Registration
+	if len(rr)%2 != 0 || len(rr) < 4 {
+		t.Fatalf("got %d registration marks", len(rr))
+	}
+	for i := 2; i < len(rr); i += 2 {
+		if d := rr[i].Y - rr[i-2].Y; d > 200 || d <= 0 {
+			t.Errorf("marks %d and %d are %v apart", i-2, i, d)
+		}
+	}
+	if r := rr[0]; r.X != 7.5 || r.Y != 7.5 || r.Shape != RegCircle || rr[1].X != 592.5 {
+		t.Errorf("got first marks %+v %+v", r, rr[1])
+	}
+}
+
+// gap is the clear distance between two placements, negative when they overlap
+func gap(a, b Placement) float64 {
+	dx := b.X - (a.X + a.W)
+	if d := a.X - (b.X + b.W); d > dx {
+		dx = d
+	}
+	dy := b.Y - (a.Y + a.H)
+	if d := a.Y - (b.Y + b.H); d > dy {
+		dy = d
+	}
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
@@ -0,0 +1,259 @@
+package packong
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount counted in minor units of its currency
+type Money struct {
+	minor int64
+	// decimals of currency; a minor unit is a 10^exp part of a unit
+	exp int
+}
+
+// most decimals a currency has, as dinars have
+const maxDecimals = 3
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for ; n > 0; n-- {
+		p *= 10
+	}
+	return p
+}
+
+// at gives m in minor units of a currency having exp decimals, not fewer than m's
+func (m Money) at(exp int) int64 {
+	return m.minor * pow10(exp-m.exp)
+}
+
+// Add sums money, keeping the most decimals of the two
+func (m Money) Add(o Money) Money {
+	exp := m.exp
+	if o.exp > exp {
+		exp = o.exp
+	}
+	return Money{m.at(exp) + o.at(exp), exp}
+}
+
+// Sub takes o out of m
+func (m Money) Sub(o Money) Money {
+	return m.Add(Money{-o.minor, o.exp})
+}
+
+// Sign is -1, 0 or 1 as money is below, at or above zero
+func (m Money) Sign() int {
+	switch {
+	case m.minor < 0:
+		return -1
+	case m.minor > 0:
+		return 1
+	}
+	return 0
+}
+
+// Float gives money in currency units
+func (m Money) Float() float64 {
+	return float64(m.minor) / float64(pow10(m.exp))
+}
+
+// String writes money as an exact decimal having currency's decimals, such as -12.05
+func (m Money) String() string {
+	sign, minor := "", m.minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	if m.exp == 0 {
+		return fmt.Sprintf("%s%d", sign, minor)
+	}
+	p := pow10(m.exp)
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/p, m.exp, minor%p)
+}
+
+// MarshalJSON writes money as an exact decimal number
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads money from a decimal number or a string holding it; null is zero
+func (m *Money) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*m = Money{}
+		return nil
+	}
+	v, err := ParseMoney(strings.Trim(string(b), `"`))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// ParseMoney reads an exact decimal having one sign at most; money keeps as many decimals
+// as written, up to three
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	if neg || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	if s == "" || strings.Trim(s, "0123456789.") != "" {
+		return Money{}, fmt.Errorf("money %q is not a decimal", s)
+	}
+
+	units, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		units, fraction = s[:i], s[i+1:]
+	}
+	if len(fraction) > maxDecimals {
+		return Money{}, fmt.Errorf("money %q has more than %d decimals", s, maxDecimals)
+	}
+	if units == "" {
+		units = "0"
+	}
+
+	u, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("money %q: %v", s, err)
+	}
+	m := Money{u * pow10(len(fraction)), len(fraction)}
+	if fraction != "" {
+		f, err := strconv.ParseInt(fraction, 10, 64)
+		if err != nil {
+			return Money{}, fmt.Errorf("money %q: %v", s, err)
+		}
+		m.minor += f
+	}
+	if neg {
+		m.minor = -m.minor
+	}
+	return m, nil
+}
+
+// Rounding tells how fractions of a minor unit are dropped
+type Rounding int
+
+const (
+	// HalfUp rounds halves away from zero
+	HalfUp Rounding = iota
+	// HalfEven rounds halves to the even neighbour
+	HalfEven
+	// Down rounds toward zero
+	Down
+	// Up rounds away from zero
+	Up
+)
+
+var roundings = map[string]Rounding{"halfup": HalfUp, "halfeven": HalfEven, "down": Down, "up": Up}
+
+// ParseRounding reads one of halfup, halfeven, down, up
+func ParseRounding(s string) (Rounding, error) {
+	r, ok := roundings[strings.ToLower(s)]
+	if !ok {
+		return HalfUp, fmt.Errorf("unknown rounding %q", s)
+	}
+	return r, nil
+}
+
+func (r Rounding) round(x float64) float64 {
+	switch r {
+	case HalfEven:
+		return math.RoundToEven(x)
+	case Down:
+		return math.Trunc(x)
+	case Up:
+		if x < 0 {
+			return math.Floor(x)
+		}
+		return math.Ceil(x)
+	}
+	return math.Round(x)
+}
+
+// Money turns an amount in currency units into money of a currency having exp decimals
+func (r Rounding) Money(v float64, exp int) Money {
+	// drop binary noise such as 0.285*100 = 28.499999999999996
+	x := math.Round(v*float64(pow10(exp))*1e6) / 1e6
+	return Money{int64(r.round(x)), exp}
+}
+
+// step rounds m to a multiple of step
+func (r Rounding) step(m, step Money) Money {
+	exp := m.exp
+	if step.exp > exp {
+		exp = step.exp
+	}
+	s := step.at(exp)
+	if s <= 1 {
+		return m
+	}
+	return Money{int64(r.round(float64(m.at(exp))/float64(s))) * s, exp}
+}
+
+// RoundingPolicy rounds every line to minor units, then the total to a multiple of Step;
+// what total rounding adds or takes goes into a line of its own so lines add up to total
+type RoundingPolicy struct {
+	Line  Rounding
+	Total Rounding
+	Step  Money
+}
+
+// name of line holding what total rounding adds or takes
+const LineRounding = "rounding"
+
+// Item is a line of a bill
+type Item struct {
+	Name   string `json:"name"`
+	Amount Money  `json:"amount"`
+	Note   string `json:"note,omitempty"`
+}
+
+// Bill is a rounded breakdown
+type Bill []Item
+
+// Total sums all items
+func (b Bill) Total() Money {
+	var t Money
+	for _, it := range b {
+		t = t.Add(it.Amount)
+	}
+	return t
+}
+
+// Bill rounds breakdown lines, after multiplying them by rate, to minor units of a currency
+// having exp decimals, then the total
+func (rp RoundingPolicy) Bill(b Breakdown, rate float64, exp int) Bill {
+	bill := Bill{}
+	for _, l := range b {
+		bill = append(bill, Item{Name: l.Name, Amount: rp.Line.Money(l.Amount*rate, exp), Note: l.Note})
+	}
+	total := bill.Total()
+	if diff := rp.Total.step(total, rp.Step).Sub(total); diff.Sign() != 0 {
+		bill = append(bill, Item{Name: LineRounding, Amount: diff})
+	}
+	return bill
+}
+
+// Rounding sets how prices are rounded
+func (op *Op) Rounding(rp RoundingPolicy) *Op {
+	op.rounding = rp
+	return op
+}
+
+// UnmarshalYAML reads money the same as json does
+func (m *Money) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	v, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
@@ -0,0 +1,610 @@
+package packong
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/innermond/packong/internal/svg"
+)
+
+// Point is a point of an outline
+type Point = svg.Point
+
+// Polygon is a closed outline
+type Polygon = svg.Polygon
+
+// Shape is a piece known by its outline; polygons are read even-odd, so inner ones are holes
+type Shape struct {
+	Label    string
+	Qty      int
+	Rotate   bool
+	Polygons []Polygon
+}
+
+// ParseShape reads a shape entry such as
+//
+//	path="M0 0 L100 0 L50 80 Z" qty=3 label=star
+//	poly="0,0 100,0 50,80" rotate=no
+//
+// path takes svg path data, poly a list of x,y points
+func ParseShape(s string) (Shape, error) {
+	sh := Shape{Qty: 1, Rotate: true}
+	tokens, err := fields(s)
+	if err != nil {
+		return sh, err
+	}
+	for _, t := range tokens {
+		kv := strings.SplitN(t, "=", 2)
+		if len(kv) != 2 {
+			return sh, fmt.Errorf("shape %q: %q needs name=value", s, t)
+		}
+		k, v := kv[0], kv[1]
+		if v, err = unquote(v); err != nil {
+			return sh, fmt.Errorf("shape %q: %s is badly quoted", s, k)
+		}
+		switch k {
+		case "path":
+			polys, err := svg.ParsePath(v)
+			if err != nil {
+				return sh, err
+			}
+			sh.Polygons = append(sh.Polygons, polys...)
+		case "poly":
+			poly := Polygon{}
+			for _, xy := range strings.Fields(v) {
+				c := strings.Split(xy, ",")
+				if len(c) != 2 {
+					return sh, fmt.Errorf("shape %q: point %q needs x,y", s, xy)
+				}
+				x, errx := strconv.ParseFloat(c[0], 64)
+				y, erry := strconv.ParseFloat(c[1], 64)
+				if errx != nil || erry != nil {
+					return sh, fmt.Errorf("shape %q: point %q is not made of numbers", s, xy)
+				}
+				poly = append(poly, Point{X: x, Y: y})
+			}
+			if len(poly) < 3 {
+				return sh, fmt.Errorf("shape %q: polygon needs at least 3 points", s)
+			}
+			sh.Polygons = append(sh.Polygons, poly)
+		case "qty":
+			if sh.Qty, err = strconv.Atoi(v); err != nil || sh.Qty < 1 {
+				return sh, fmt.Errorf("shape %q: qty %q must be a whole number greater than zero", s, v)
+			}
+		case "rotate":
+			if sh.Rotate, err = parseYesNo(v); err != nil {
+				return sh, fmt.Errorf("shape %q: rotate %q is not yes or no", s, v)
+			}
+		case "label":
+			sh.Label = v
+		default:
+			return sh, fmt.Errorf("shape %q: unknown attribute %q", s, k)
+		}
+	}
+	if len(sh.Polygons) == 0 {
+		return sh, fmt.Errorf("shape %q needs a path or a poly", s)
+	}
+	return sh, nil
+}
+
+// Nesting tells how shapes are nested
+type Nesting struct {
+	// rotation step in degrees; zero keeps shapes as drawn
+	Step float64
+	// gap kept between outlines
+	Spacing float64
+	// side of grid cells outlines are laid on; zero picks one from sheet width
+	Resolution float64
+}
+
+// bounds gives the box enclosing polygons
+func bounds(polys []Polygon) (minx, miny, maxx, maxy float64) {
+	minx, miny = math.Inf(1), math.Inf(1)
+	maxx, maxy = math.Inf(-1), math.Inf(-1)
+	for _, poly := range polys {
+		for _, p := range poly {
+			minx, maxx = math.Min(minx, p.X), math.Max(maxx, p.X)
+			miny, maxy = math.Min(miny, p.Y), math.Max(maxy, p.Y)
+		}
+	}
+	return
+}
+
+// turn rotates polygons by angle degrees then moves them to touch x and y axes
+func turn(polys []Polygon, angle float64) []Polygon {
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	out := make([]Polygon, len(polys))
+	for i, poly := range polys {
+		out[i] = make(Polygon, len(poly))
+		for j, p := range poly {
+			out[i][j] = Point{X: p.X*cos - p.Y*sin, Y: p.X*sin + p.Y*cos}
+		}
+	}
+	minx, miny, _, _ := bounds(out)
+	return move(out, -minx, -miny)
+}
+
+func move(polys []Polygon, dx, dy float64) []Polygon {
+	out := make([]Polygon, len(polys))
+	for i, poly := range polys {
+		out[i] = make(Polygon, len(poly))
+		for j, p := range poly {
+			out[i][j] = Point{X: p.X + dx, Y: p.Y + dy}
+		}
+	}
+	return out
+}
+
+// inside tells p is inside poly
+func inside(p Point, poly Polygon) bool {
+	in := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			in = !in
+		}
+	}
+	return in
+}
+
+// area of polygons read even-odd, and length of their outlines
+func measure(polys []Polygon) (area, perim float64) {
+	for i, poly := range polys {
+		a := 0.0
+		for k, j := 0, len(poly)-1; k < len(poly); j, k = k, k+1 {
+			a += poly[j].X*poly[k].Y - poly[k].X*poly[j].Y
+			perim += math.Hypot(poly[k].X-poly[j].X, poly[k].Y-poly[j].Y)
+		}
+		a = math.Abs(a) / 2
+		// a polygon inside an odd number of others is a hole
+		depth := 0
+		for j, other := range polys {
+			if j != i && inside(poly[0], other) {
+				depth++
+			}
+		}
+		if depth%2 == 1 {
+			a = -a
+		}
+		area += a
+	}
+	return
+}
+
+// mask is an outline laid on grid: filled cells of every row as [from, to) spans
+type mask struct {
+	w, h  int
+	spans [][][2]int
+}
+
+// rasterize lays polygons, touching both axes, on cells of side r;
+// every cell the outline touches is filled
+func rasterize(polys []Polygon, r float64) mask {
+	_, _, maxx, maxy := bounds(polys)
+	m := mask{w: int(math.Ceil(maxx/r - 1e-9)), h: int(math.Ceil(maxy/r - 1e-9))}
+	if m.w == 0 {
+		m.w = 1
+	}
+	if m.h == 0 {
+		m.h = 1
+	}
+	m.spans = make([][][2]int, m.h)
+	for row := 0; row < m.h; row++ {
+		filled := make([]bool, m.w)
+		// a few scan lines across row catch what it holds
+		for k := 0; k <= 4; k++ {
+			y := (float64(row) + float64(k)/4) * r
+			xs := []float64{}
+			for _, poly := range polys {
+				for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+					a, b := poly[i], poly[j]
+					if (a.Y > y) != (b.Y > y) {
+						xs = append(xs, (b.X-a.X)*(y-a.Y)/(b.Y-a.Y)+a.X)
+					}
+				}
+			}
+			sort.Float64s(xs)
+			for i := 0; i+1 < len(xs); i += 2 {
+				fill(filled, xs[i]/r, xs[i+1]/r)
+			}
+		}
+		// edges crossing row
+		y0, y1 := float64(row)*r, float64(row+1)*r
+		for _, poly := range polys {
+			for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+				a, b := poly[j], poly[i]
+				lo, hi := math.Max(math.Min(a.Y, b.Y), y0), math.Min(math.Max(a.Y, b.Y), y1)
+				if lo > hi {
+					continue
+				}
+				xa, xb := a.X, b.X
+				if a.Y != b.Y {
+					xa = a.X + (b.X-a.X)*(lo-a.Y)/(b.Y-a.Y)
+					xb = a.X + (b.X-a.X)*(hi-a.Y)/(b.Y-a.Y)
+				}
+				fill(filled, math.Min(xa, xb)/r, math.Max(xa, xb)/r)
+			}
+		}
+		m.spans[row] = spans(filled)
+	}
+	return m
+}
+
+// fill marks cells from x0 to x1, given in cells
+func fill(filled []bool, x0, x1 float64) {
+	// a cell only touched on its left side stays empty
+	from, to := int(math.Floor(x0)), int(math.Ceil(x1-1e-9))-1
+	if to < from {
+		to = from
+	}
+	if from < 0 {
+		from = 0
+	}
+	if to >= len(filled) {
+		to = len(filled) - 1
+	}
+	for c := from; c <= to; c++ {
+		filled[c] = true
+	}
+}
+
+func spans(filled []bool) [][2]int {
+	ss := [][2]int{}
+	for c := 0; c < len(filled); c++ {
+		if !filled[c] {
+			continue
+		}
+		from := c
+		for c < len(filled) && filled[c] {
+			c++
+		}
+		ss = append(ss, [2]int{from, c})
+	}
+	return ss
+}
+
+// grown gives mask enlarged by d cells on every side; its origin moves by -d, -d
+func (m mask) grown(d int) mask {
+	if d == 0 {
+		return m
+	}
+	g := mask{w: m.w + 2*d, h: m.h + 2*d}
+	g.spans = make([][][2]int, g.h)
+	for row := 0; row < g.h; row++ {
+		filled := make([]bool, g.w)
+		for src := row - 2*d; src <= row; src++ {
+			if src < 0 || src >= m.h {
+				continue
+			}
+			for _, s := range m.spans[src] {
+				for c := s[0]; c < s[1]+2*d; c++ {
+					filled[c] = true
+				}
+			}
+		}
+		g.spans[row] = spans(filled)
+	}
+	return g
+}
+
+// grid is a sheet divided into cells, filled where outlines lay
+type grid struct {
+	cols, rows int
+	cells      []bool
+	// rows up to here hold something
+	used int
+}
+
+func newGrid(cols, rows int) *grid {
+	return &grid{cols: cols, rows: rows, cells: make([]bool, cols*rows)}
+}
+
+// find gives the top most, then left most, cell where m fits
+func (g *grid) find(m mask) (int, int, bool) {
+	last := g.rows - m.h
+	if g.used < last {
+		// below everything there is always room
+		last = g.used
+	}
+	for y := 0; y <= last; y++ {
+		for x := 0; x+m.w <= g.cols; {
+			next := g.collides(m, x, y)
+			if next < 0 {
+				return x, y, true
+			}
+			x = next
+		}
+	}
+	return 0, 0, false
+}
+
+// collides gives -1 when m fits at x, y; otherwise the next x worth trying
+func (g *grid) collides(m mask, x, y int) int {
+	for row, ss := range m.spans {
+		base := (y + row) * g.cols
+		for _, s := range ss {
+			for c := x + s[0]; c < x+s[1]; c++ {
+				if g.cells[base+c] {
+					// any x keeping cell c inside span collides too
+					return c - s[0] + 1
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// put fills cells of m at x, y; m may reach outside grid
+func (g *grid) put(m mask, x, y int) {
+	for row, ss := range m.spans {
+		gy := y + row
+		if gy < 0 || gy >= g.rows {
+			continue
+		}
+		for _, s := range ss {
+			for c := x + s[0]; c < x+s[1]; c++ {
+				if c >= 0 && c < g.cols {
+					g.cells[gy*g.cols+c] = true
+				}
+			}
+		}
+		if gy+1 > g.used {
+			g.used = gy + 1
+		}
+	}
+}
+
+// nested is a shape placed on a sheet
+type nested struct {
+	polys []Polygon
+	sheet int
+}
+
+// Nest packs shapes by their outlines, instead of their enclosing boxes
+func (op *Op) Nest(shapes []Shape, n Nesting) (*Report, []FitReader, error) {
+	if op.unitErr != nil {
+		return nil, nil, op.unitErr
+	}
+	op.reserve()
+	rw, rh := op.room()
+	if rw <= 0 || rh <= 0 {
+		return nil, nil, errors.New("margins leave no room on sheet")
+	}
+	r := n.Resolution
+	if r <= 0 {
+		r = rw / 400
+	}
+	cols, rows := int(rw/r), int(rh/r)
+	// outlines keep apart as boxes do: by cut, gutter and bleed of both neighbours
+	gap := n.Spacing + op.cutwidth + op.gutter + 2*op.bleed
+	d := int(math.Ceil(gap/r - 1e-9))
+
+	angles := []float64{0}
+	if n.Step > 0 {
+		for a := n.Step; a < 360-1e-9; a += n.Step {
+			angles = append(angles, a)
+		}
+	}
+
+	// limits are told before any outline is rasterized
+	pieces := 0
+	for _, sh := range shapes {
+		if err := over("qty", float64(op.limits.MaxQty), float64(sh.Qty)); err != nil {
+			return nil, nil, err
+		}
+		pieces += sh.Qty
+	}
+	if err := over("pieces", float64(op.limits.MaxPieces), float64(pieces)); err != nil {
+		return nil, nil, err
+	}
+
+	// every copy of a shape is a piece
+	type item struct {
+		piece  *Piece
+		turns  [][]Polygon
+		masks  []mask
+		angles []float64
+		area   float64
+		perim  float64
+	}
+	items := []*item{}
+	for _, sh := range shapes {
+		minx, miny, maxx, maxy := bounds(sh.Polygons)
+		it := &item{}
+		it.area, it.perim = measure(sh.Polygons)
+		aa := angles
+		if !sh.Rotate {
+			aa = []float64{0}
+		}
+		for _, a := range aa {
+			t := turn(sh.Polygons, a)
+			it.turns = append(it.turns, t)
+			it.masks = append(it.masks, rasterize(t, r))
+			it.angles = append(it.angles, a)
+		}
+		for q := 0; q < sh.Qty; q++ {
+			c := *it
+			c.piece = &Piece{ID: len(items) + 1, Label: sh.Label, W: maxx - minx, H: maxy - miny}
+			items = append(items, &c)
+		}
+	}
+	// largest first
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].area > items[j].area
+	})
+
+	grids := []*grid{}
+	placed := []nested{}
+	layout := []Placement{}
+	unfit := []Unfit{}
+	boxesArea, boxesPerim := 0.0, 0.0
+	for _, it := range items {
+		done := false
+		for s := 0; s <= len(grids) && !done; s++ {
+			if s == len(grids) {
+				grids = append(grids, newGrid(cols, rows))
+			}
+			g := grids[s]
+			// turn reaching least down the sheet wins, then the left most
+			best, bx, by, reach := -1, 0, 0, 0
+			for k, m := range it.masks {
+				x, y, ok := g.find(m)
+				if ok && (best < 0 || y+m.h < reach || y+m.h == reach && x < bx) {
+					best, bx, by, reach = k, x, y, y+m.h
+				}
+			}
+			if best < 0 {
+				if g.used == 0 {
+					// an empty sheet can't take it; no sheet can
+					grids = grids[:s]
+					break
+				}
+				continue
+			}
+			// a sheet counts once a piece lays on it
+			if g.used == 0 {
+				if err := over("sheets", float64(op.limits.MaxSheets), float64(s+1)); err != nil {
+					return nil, nil, err
+				}
+			}
+			g.put(it.masks[best].grown(d), bx-d, by-d)
+			X, Y := op.margins.Left+float64(bx)*r, op.margins.Top+float64(by)*r
+			polys := move(it.turns[best], X, Y)
+			_, _, maxx, maxy := bounds(it.turns[best])
+			placed = append(placed, nested{polys, s + 1})
+			layout = append(layout, Placement{
+				Piece:   it.piece,
+				Sheet:   s + 1,
+				X:       X,
+				Y:       Y,
+				W:       maxx,
+				H:       maxy,
+				Rotated: it.angles[best] != 0,
+				Angle:   it.angles[best],
+			})
+			boxesArea += it.area
+			boxesPerim += it.perim
+			done = true
+		}
+		if !done {
+			unfit = append(unfit, Unfit{
+				Piece:  it.piece,
+				Reason: UnfitTooLarge,
+				Note:   fmt.Sprintf("outline %vx%v fits no sheet %vx%v in any allowed rotation", it.piece.W, it.piece.H, op.width, op.height),
+			})
+		}
+	}
+
+	// sheets are as long as what they hold when tight
+	usedArea, vendoredArea, vendoredLength := 0.0, 0.0, 0.0
+	lengths := []float64{}
+	for s := range grids {
+		l := op.height
+		if op.tight {
+			l = op.margins.Top + op.margins.Bottom
+			for _, pl := range layout {
+				if pl.Sheet == s+1 {
+					l = math.Max(l, pl.Y+pl.H+op.margins.Bottom)
+				}
+			}
+		}
+		lengths = append(lengths, l)
+		usedArea += op.width * l
+		if op.vendorsellint {
+			vendoredArea += math.Ceil(l/op.k) * op.k * op.width
+		} else {
+			vendoredArea = usedArea
+		}
+	}
+	vendoredLength = vendoredArea / op.width
+
+	outs := []FitReader{}
+	if op.outname != "" {
+		for s, l := range lengths {
+			shapes := [][]Polygon{}
+			for _, nd := range placed {
+				if nd.sheet == s+1 {
+					shapes = append(shapes, nd.polys)
+				}
+			}
+			outs = append(outs, op.renderNest(s+1, len(lengths), l, shapes))
+		}
+	}
+
+	lostArea := usedArea - boxesArea
+	if op.vendorsellint {
+		lostArea = vendoredArea - boxesArea
+	}
+	procentArea := 0.0
+	if usedArea > 0 {
+		procentArea = boxesArea * 100 / usedArea
+	}
+	rep := &Report{
+		WiningStrategyName: "nest",
+		BoxesArea:          boxesArea / op.k2,
+		UsedArea:           usedArea / op.k2,
+		VendoredArea:       vendoredArea / op.k2,
+		VendoredLength:     vendoredLength / op.k,
+		VendoredWidth:      op.width / op.k,
+		LostArea:           lostArea / op.k2,
+		ProcentArea:        procentArea,
+		BoxesPerim:         boxesPerim / op.k,
+		Banding:            map[string]float64{},
+		UnfitLen:           len(unfit),
+		Unfit:              unfit,
+		NumSheetUsed:       float64(len(grids)),
+		Layout:             layout,
+		Registration:       op.registration(layout),
+	}
+	if err := op.bill(rep, op.priceModel().Price(rep), op.costs().Costs(rep)); err != nil {
+		return nil, nil, err
+	}
+	return rep, outs, nil
+}
+
+// renderNest produces svg of a sheet l long holding shapes
+func (op *Op) renderNest(inx, total int, l float64, shapes [][]Polygon) FitReader {
+	fn := fmt.Sprintf("%s.%d.nest.svg", op.outname, inx)
+
+	w := op.width
+	th := 0.0
+	if op.titleBlock || op.legend {
+		th = svg.TitleBlockHeight(w)
+	}
+	var s string
+	if op.outweb {
+		s = svg.StartWeb(w, l+th, op.plain)
+	} else {
+		unit, scale := op.lengthUnit.svgUnit()
+		s = svg.StartAt(0, 0, w, l+th, unit, scale, op.plain)
+	}
+	si := svg.Outlines(shapes, op.outline, op.plain)
+	if reg := op.regMarks(inx, l); len(reg) > 0 {
+		si += svg.Marks(nil, svgMarks(reg), op.plain)
+	}
+	if th > 0 {
+		area := 0.0
+		for _, polys := range shapes {
+			a, _ := measure(polys)
+			area += a
+		}
+		si += svg.TitleBlock(l, w, th, svg.TitleInfo{
+			Job:         op.job,
+			Material:    op.material,
+			Sheet:       inx,
+			Sheets:      total,
+			W:           w,
+			H:           l,
+			Unit:        op.unit,
+			Utilisation: area * 100 / (w * l),
+			Date:        time.Now().Format("2006-01-02"),
+		}, op.titleBlock, op.legend, op.plain)
+	}
+	s += svg.End(si)
+	return FitReader{fn: strings.NewReader(s)}
+}
@@ -0,0 +1,102 @@
+package packong
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseShape(t *testing.T) {
+	sh, err := ParseShape(`path="M0 0 h100 v50 H0 z M10 10 l20 0 l0 20 l-20 0 z" qty=2 label=plate`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sh.Qty != 2 || sh.Label != "plate" || len(sh.Polygons) != 2 {
+		t.Fatalf("got %+v", sh)
+	}
+	// inner square is a hole
+	if area, perim := measure(sh.Polygons); area != 100*50-20*20 || perim != 300+80 {
+		t.Errorf("got area %v and perimeter %v", area, perim)
+	}
+
+	sh, err = ParseShape(`path="M0,0 A50,50 0 0 1 100,0 Z"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// half of a disc
+	if area, _ := measure(sh.Polygons); math.Abs(area-math.Pi*50*50/2) > 50 {
+		t.Errorf("got half disc area %v", area)
+	}
+
+	for _, s := range []string{`poly="0,0 10,0"`, `path="0 0 L10 10"`, `qty=2`, `path="M0 0 L10 0 L5 5 Z" colour=red`} {
+		if _, err := ParseShape(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestNest(t *testing.T) {
+	// right triangles pair up into squares when turned half a turn
+	tri, err := ParseShape(`poly="0,0 100,0 0,100" qty=8`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := NewOp(220, 1000, nil, "mm").Outname("nest")
+	rep, outs, err := op.Nest([]Shape{tri}, Nesting{Step: 90, Spacing: 2, Resolution: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Layout) != 8 || rep.UnfitLen != 0 {
+		t.Fatalf("got %d placed and %d unfit", len(rep.Layout), rep.UnfitLen)
+	}
+	// by bounding boxes 8 triangles would need 400 long
+	if l := rep.UsedArea / rep.VendoredWidth * 1000; l > 320 {
+		t.Errorf("got sheet %v long, expected triangles to interlock", l)
+	}
+	if len(outs) != 1 {
+		t.Fatalf("got %d drawings, expected 1", len(outs))
+	}
+	for _, r := range outs {
+		b := new(strings.Builder)
+		for _, rd := range r {
+			buf := make([]byte, 1<<16)
+			n, _ := rd.Read(buf)
+			b.Write(buf[:n])
+		}
+		if strings.Count(b.String(), "<path") != 8 {
+			t.Errorf("expected 8 outlines drawn")
+		}
+	}
+
+	big, _ := ParseShape(`poly="0,0 300,0 0,300"`)
+	rep, _, err = op.Nest([]Shape{big}, Nesting{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.UnfitLen != 1 || rep.NumSheetUsed != 0 {
+		t.Errorf("got %d unfit on %v sheets, expected it left out", rep.UnfitLen, rep.NumSheetUsed)
+	}
+}
+
+func TestNestGap(t *testing.T) {
+	sq, _ := ParseShape(`poly="0,0 100,0 100,100 0,100" qty=2 rotate=no`)
+	for _, op := range []*Op{
+		NewOp(205, 1000, nil, "mm").Gutter(10),
+		NewOp(205, 1000, nil, "mm").Cutwidth(10),
+		NewOp(205, 1000, nil, "mm").Bleed(5),
+		NewOp(211, 1000, nil, "mm").Marks(Marks{Crop: 3}),
+	} {
+		rep, _, err := op.Nest([]Shape{sq}, Nesting{Resolution: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rep.Layout) != 2 {
+			t.Fatalf("got %d placed, expected 2", len(rep.Layout))
+		}
+		// no room for two side by side once they keep apart
+		a, b := rep.Layout[0], rep.Layout[1]
+		if a.X != b.X || math.Abs(a.Y-b.Y) < 105 {
+			t.Errorf("got squares at %v,%v and %v,%v, expected one under the other apart", a.X, a.Y, b.X, b.Y)
+		}
+	}
+}
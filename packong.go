@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/innermond/packong/internal/svg"
 	"github.com/innermond/pak"
@@ -21,11 +22,11 @@ const (
 
 // strategies used for packing boxes on mother box
 var strategies = map[string]*pak.Base{
-	"BestAreaFit":      &pak.Base{&pak.BestAreaFit{}},
-	"BestLongSide":     &pak.Base{&pak.BestLongSide{}},
-	"BestShortSide":    &pak.Base{&pak.BestShortSide{}},
-	"BottomLeft":       &pak.Base{&pak.BottomLeft{}},
-	"BestSimilarRatio": &pak.Base{&pak.BestSimilarRatio{}},
+	"BestAreaFit":      &pak.Base{Scorer: &pak.BestAreaFit{}},
+	"BestLongSide":     &pak.Base{Scorer: &pak.BestLongSide{}},
+	"BestShortSide":    &pak.Base{Scorer: &pak.BestShortSide{}},
+	"BottomLeft":       &pak.Base{Scorer: &pak.BottomLeft{}},
+	"BestSimilarRatio": &pak.Base{Scorer: &pak.BestSimilarRatio{}},
 }
 
 // Op describe a boxes packing operation
@@ -34,8 +35,16 @@ type Op struct {
 	dimensions []string
 	// filename of a graphic file (svg) with boxes packed
 	outname string
-	// measurement unit: mm, cm
+	// measurement unit: mm, cm, m, in, ft or any registered one
 	unit string
+	// unit's description; unitErr tells unit is not registered
+	lengthUnit Unit
+	unitErr    error
+	// bounds of job's size
+	limits Limits
+	// pieces too large for sheet are split into panels overlapping that much
+	tiling  bool
+	overlap float64
 	// mother box dimensions
 	width, height float64
 	// when true boxes area is surround exactly area boxes swarm
@@ -44,11 +53,17 @@ type Op struct {
 	plain bool
 	// will rendered "wxh" dimensions pair on every box
 	showDim bool
+	// font size bounds of dimensions, in real units
+	minFont, maxFont float64
 	// amount of expanding area's box in order to accomodate to loosing material
 	// when a physical cut (that has real width which eats from box area) occurs
 	cutwidth float64
-	// point from where boxes are lay down
-	topleftmargin float64
+	// gap kept between pieces, apart from cut width
+	gutter float64
+	// sheet edges kept free of boxes
+	margins Margins
+	// how much pieces extend past their trim edges, unless they tell otherwise
+	bleed float64
 
 	// prices:
 	// mu - material used, a price that reflects man's work
@@ -56,6 +71,27 @@ type Op struct {
 	// pp - perimeter price, a price connected with number of cuts needed for breaking big sheet to needed pieces
 	// pd - price of moving to location
 	mu, ml, pp, pd float64
+	// pb - banding price per linear meter of edge tape
+	pb float64
+	// replaces standard price model made of prices above
+	model PriceModel
+	// price list and the customer group it is looked up for
+	list  *PriceList
+	group string
+
+	// ph - labour cost per square meter of used material
+	ph float64
+	// price follows from costs, by a target margin or set by hand
+	costplus       bool
+	margin, manual float64
+
+	// currency of reported prices and its exchange rates
+	currency string
+	rates    Rates
+	// value added tax percent
+	vat float64
+	// how prices are rounded to minor units
+	rounding RoundingPolicy
 
 	// it considers lost material as valuable as used material
 	greedy bool
@@ -70,6 +106,26 @@ type Op struct {
 
 	// outline, no fill
 	outline bool
+
+	// title block rendered under every sheet
+	titleBlock    bool
+	job, material string
+	// colours legend rendered into title block
+	legend bool
+
+	// artwork of pieces placed into sheets, embedded or linked;
+	// embedded artwork is read by open and kept by name as data uri
+	impose, embed bool
+	open          ArtOpener
+	hrefs         map[string]string
+
+	// crop and registration marks
+	marks Marks
+	// a single kind of rectangle fills whole sheets, as HowMany asks
+	fill bool
+
+	// pieces behind boxes made by BoxesFromString
+	pieces map[*pak.Box]*Piece
 }
 
 func NewOp(w, h float64, dd []string, u string) *Op {
@@ -84,15 +140,21 @@ func NewOp(w, h float64, dd []string, u string) *Op {
 
 		greedy:        false,
 		vendorsellint: true,
+
+		limits: DefaultLimits,
 	}
 
-	op.k, op.k2 = op.kk()
+	op.k, op.k2, op.unitErr = op.kk()
+	// dimensions are readable from 3mm up to 50mm
+	op.minFont, op.maxFont = 0.003*op.k, 0.05*op.k
 
 	return op
 }
 
+// Topleft sets the same margin on top and left edges;
+// as with Margins the top one is part of used length
 func (op *Op) Topleft(tl float64) *Op {
-	op.topleftmargin = tl
+	op.margins.Top, op.margins.Left = tl, tl
 	return op
 }
 
@@ -132,11 +194,25 @@ func (op *Op) Appearance(yesno ...bool) *Op {
 	return op
 }
 
+// DimFont bounds font size of rendered dimensions; sizes are in op's unit
+func (op *Op) DimFont(min, max float64) *Op {
+	op.minFont = min
+	op.maxFont = max
+	return op
+}
+
 func (op *Op) Cutwidth(cw float64) *Op {
 	op.cutwidth = cw
 	return op
 }
 
+// Gutter sets the gap kept between pieces, such as a heat zone of a laser;
+// unlike cut width it leaves pieces' size alone
+func (op *Op) Gutter(g float64) *Op {
+	op.gutter = g
+	return op
+}
+
 func (op *Op) Outname(name string) *Op {
 	op.outname = name
 	return op
@@ -147,6 +223,26 @@ func (op *Op) Outweb(val bool) *Op {
 	return op
 }
 
+// Title adds a title block with job name and material under every sheet
+func (op *Op) Title(job, material string) *Op {
+	op.titleBlock = true
+	op.job = job
+	op.material = material
+	return op
+}
+
+// Material names material of the big box; price lists are looked up by it
+func (op *Op) Material(material string) *Op {
+	op.material = material
+	return op
+}
+
+// Legend adds a legend explaining the colours of boxes
+func (op *Op) Legend(show bool) *Op {
+	op.legend = show
+	return op
+}
+
 func (op *Op) Price(mu, ml, pp, pd float64) *Op {
 	op.mu = mu
 	op.ml = ml
@@ -155,6 +251,12 @@ func (op *Op) Price(mu, ml, pp, pd float64) *Op {
 	return op
 }
 
+// BandingPrice sets price of a linear meter of edge tape
+func (op *Op) BandingPrice(pb float64) *Op {
+	op.pb = pb
+	return op
+}
+
 func (op *Op) Greedy(mood bool) *Op {
 	op.greedy = mood
 	return op
@@ -165,29 +267,44 @@ func (op *Op) VendorSellInt(sell bool) *Op {
 	return op
 }
 
-func (op *Op) kk() (float64, float64) {
-	k := 1000.0
-	switch op.unit {
-	case "cm":
-		k = 100.0
-	case "m":
-		k = 1.0
+func (op *Op) kk() (float64, float64, error) {
+	u, err := LookupUnit(op.unit)
+	if err != nil {
+		// keeps scale factors usable, errors come up when op is used
+		u, _ = LookupUnit("mm")
 	}
+	op.lengthUnit = u
+	k := u.PerMeter
 	k2 := k * k
 
-	return k, k2
+	return k, k2, err
 }
 
 func (op *Op) NumStrategy() int {
 	return len(strategies)
 }
 
+// CheckCandidates tells whether packing that many arrangements by every strategy
+// goes over candidates limit; it is asked before arrangements are made
+func (op *Op) CheckCandidates(arrangements float64) error {
+	return over("candidates", float64(op.limits.MaxCandidates), arrangements*float64(len(strategies)))
+}
+
 func (op *Op) Fit(pp [][]*pak.Box, deep bool) (*Report, []FitReader, error) {
+	if op.unitErr != nil {
+		return nil, nil, op.unitErr
+	}
+
+	if err := op.CheckCandidates(float64(len(pp))); err != nil {
+		return nil, nil, err
+	}
 
 	wins := map[string][]float64{}
 	done := map[string][]*pak.Box{}
 	remnants := map[string][]*pak.Box{}
 	outputFn := map[string][]FitReader{}
+	layouts := map[string][]Placement{}
+	unfits := map[string][]Unfit{}
 	mx := sync.Mutex{}
 
 	var wg sync.WaitGroup
@@ -208,14 +325,20 @@ pieced:
 			for strategyName, strategy := range strategies {
 				sn := strategyName + ".perm." + strconv.Itoa(i+pix)
 				s := strategy
+				permutated := permutated
 				// unsorted
 				go func() {
 					bb := []*pak.Box{}
+					// copies keep pointing to pieces of originals
+					pieces := map[*pak.Box]*Piece{}
 					for _, box := range permutated {
-						bb = append(bb, &pak.Box{W: box.W, H: box.H, CanRotate: box.CanRotate})
+						b := &pak.Box{W: box.W, H: box.H, CanRotate: box.CanRotate}
+						pieces[b] = op.pieces[box]
+						bb = append(bb, b)
 					}
 					mx.Lock()
-					wins[sn], done[sn], remnants[sn], outputFn[sn] = op.matchboxes(sn, s, bb)
+					wins[sn], done[sn], remnants[sn], outputFn[sn], layouts[sn] = op.matchboxes(sn, s, bb, pieces)
+					unfits[sn] = op.diagnose(remnants[sn], pieces)
 					defer mx.Unlock()
 					defer wg.Done()
 				}()
@@ -258,7 +381,14 @@ pieced:
 	if !ok {
 		return nil, nil, errors.New("outFns error")
 	}
+	layout, ok := layouts[winingStrategyName]
+	if !ok {
+		return nil, nil, errors.New("layout error")
+	}
 	usedArea, vendoredArea, vendoredLength, boxesArea, boxesPerim, numSheetsUsed := best[0], best[1], best[2], best[3], best[4], best[5]
+	if err := over("sheets", float64(op.limits.MaxSheets), numSheetsUsed); err != nil {
+		return nil, nil, err
+	}
 	lostArea := usedArea - boxesArea
 	if op.vendorsellint {
 		lostArea = vendoredArea - boxesArea
@@ -273,9 +403,13 @@ pieced:
 	vendoredLength = vendoredLength / op.k
 	lostArea = lostArea / op.k2
 	boxesPerim = boxesPerim / op.k
-	price := boxesArea*op.mu + lostArea*op.ml + boxesPerim*op.pp + op.pd
-	if op.greedy {
-		price = boxesArea*op.mu + lostArea*op.mu + boxesPerim*op.pp + op.pd
+	// edge tape needed by placed pieces, by tape
+	banding := map[string]float64{}
+	for _, pl := range layout {
+		if pl.Piece == nil || pl.Piece.Banding == nil {
+			continue
+		}
+		banding[pl.Piece.Banding.Tape] += pl.Piece.Banding.length(pl.Piece.W, pl.Piece.H) / op.k
 	}
 	rep := &Report{
 		WiningStrategyName: winingStrategyName,
@@ -287,73 +421,166 @@ pieced:
 		LostArea:           lostArea,
 		ProcentArea:        procentArea,
 		BoxesPerim:         boxesPerim,
-		Price:              price,
+		Banding:            banding,
 		UnfitLen:           len(boxes),
 		UnfitCode:          pak.BoxCode(boxes),
 		FitCode:            pak.BoxCode(fitboxes),
+		Unfit:              unfits[winingStrategyName],
 		NumSheetUsed:       numSheetsUsed,
+		Layout:             layout,
+		Registration:       op.registration(layout),
+	}
+	if err := op.bill(rep, op.priceModel().Price(rep), op.costs().Costs(rep)); err != nil {
+		return nil, nil, err
 	}
 
 	return rep, outFns, nil
 }
 
 func (op *Op) BoxesFromString() (boxes []*pak.Box, err error) {
-	for _, dd := range op.dimensions {
-		d := strings.Split(dd, "x")
-		if len(d) == 2 {
-			d = append(d, "1", "1") // repeat 1 time
-		} else if len(d) == 3 {
-			d = append(d, "1") // can rotate
+	if op.unitErr != nil {
+		return nil, op.unitErr
+	}
+	if err := over("width", op.limits.MaxWidth, op.width); err != nil {
+		return nil, err
+	}
+	if err := over("height", op.limits.MaxHeight, op.height); err != nil {
+		return nil, err
+	}
+	op.reserve()
+	op.pieces = map[*pak.Box]*Piece{}
+	var (
+		errs     ValidationError
+		limitErr error
+		// pieces made so far
+		ids int
+		// pieces all entries ask for, told before they are made
+		asked int
+	)
+	for i, dd := range op.dimensions {
+		ps, err := ParseSpec(dd)
+		if ve, ok := err.(ValidationError); ok {
+			for _, inv := range ve {
+				inv.Entry = i
+				errs = append(errs, inv)
+			}
+			continue
 		}
-
-		w, err := strconv.ParseFloat(d[0], 64)
-		if err != nil {
-			return nil, err
-		} else if w <= 0 {
-			err = fmt.Errorf("greater than zero condition; received %f", w)
-			return nil, err
+		// a limit gone over is told once every entry is checked
+		if limitErr == nil {
+			limitErr = over("qty", float64(op.limits.MaxQty), float64(ps.Qty))
 		}
 
-		h, err := strconv.ParseFloat(d[1], 64)
-		if err != nil {
-			return nil, err
-		} else if h <= 0 {
-			err = fmt.Errorf("greater than zero condition; received %f", h)
-			return nil, err
+		cw, ch := ps.Banding.cutSize(ps.W, ps.H)
+		if cw <= 0 || ch <= 0 {
+			errs = append(errs, Invalid{i, dd, "band", fmt.Sprintf("thickness eats whole piece %vx%v", ps.W, ps.H)})
+			continue
 		}
-
-		n, err := strconv.Atoi(d[2])
-		if err != nil {
-			return nil, err
-		} else if n < 1 {
-			err = fmt.Errorf("greater than zero condition; received %q", n)
-			return nil, err
-		} else if n > 50 {
-			err = fmt.Errorf("lesser than peak condition; received %f", w)
-			return nil, err
+		if len(errs) > 0 || limitErr != nil {
+			// only checking what follows
+			continue
 		}
-
-		r, err := strconv.ParseBool(d[3])
+		label := ps.Label
+		if label == "" {
+			label = PieceSpec{W: ps.W, H: ps.H, Qty: 1, Rotate: true, Form: ps.Form}.String()
+		}
+		bleed := ps.Bleed
+		if bleed == 0 {
+			bleed = op.bleed
+		}
+		if ps.Art != "" && op.impose {
+			if _, err := op.href(ps.Art); err != nil {
+				errs = append(errs, Invalid{i, dd, "art", err.Error()})
+				continue
+			}
+		}
+		tt, err := op.tile(ps.W, ps.H, ps.Rotate, ps.Banding, bleed)
 		if err != nil {
-			return nil, err
+			errs = append(errs, Invalid{i, dd, "entry", err.Error()})
+			continue
 		}
-
-		for n != 0 {
-			var val = &pak.Box{W: w + op.cutwidth, H: h + op.cutwidth, CanRotate: r}
-			boxes = append(boxes, val)
-			n--
+		asked += ps.Qty * len(tt)
+		if limitErr = over("pieces", float64(op.limits.MaxPieces), float64(asked)); limitErr != nil {
+			continue
 		}
+		newPiece := func(t tiled) *Piece {
+			ids++
+			pc := &Piece{
+				ID:       ids,
+				Label:    label,
+				W:        t.w,
+				H:        t.h,
+				Material: ps.Material,
+				Priority: ps.Priority,
+				Banding:  t.band,
+				Bleed:    bleed,
+				Panel:    t.panel,
+				Form:     ps.Form,
+				Radius:   ps.Radius,
+				Art:      ps.Art,
+			}
+			if t.panel != nil {
+				pc.Label += " " + t.panel.String()
+			}
+			return pc
+		}
+		// a circle looks the same turned
+		canRotate := ps.Rotate || ps.Form == FormCircle
+		qty := ps.Qty
+		if len(tt) == 1 && tt[0].panel == nil && (ps.Form != FormRect || op.fill) {
+			bw, bh := cw+2*bleed+op.cutwidth+op.gutter, ch+2*bleed+op.cutwidth+op.gutter
+			var bb []block
+			if ps.Form != FormRect {
+				// round pieces nest into hollows of staggered rows
+				bb = op.staggered(bw, bh, qty)
+			} else {
+				bb = op.filled(bw, bh, canRotate, qty)
+			}
+			for _, bl := range bb {
+				st := &stagger{w: bw - op.gutter, h: bh - op.gutter, turned: bl.turned}
+				for _, at := range bl.at {
+					st.pieces = append(st.pieces, newPiece(tt[0]))
+					st.at = append(st.at, at)
+				}
+				val := &pak.Box{W: bl.w, H: bl.h, CanRotate: canRotate}
+				boxes = append(boxes, val)
+				op.pieces[val] = &Piece{Label: label, W: bl.w, H: bl.h, Priority: ps.Priority, stagger: st}
+				qty -= len(bl.at)
+			}
+		}
+		for n := qty; n != 0; n-- {
+			for _, t := range tt {
+				cw, ch := t.band.cutSize(t.w, t.h)
+				// bleed is printed and cut away as part of piece
+				// gutter goes on right and bottom, taken back once placed
+				var val = &pak.Box{W: cw + 2*bleed + op.cutwidth + op.gutter, H: ch + 2*bleed + op.cutwidth + op.gutter, CanRotate: canRotate}
+				boxes = append(boxes, val)
+				op.pieces[val] = newPiece(t)
+			}
+		}
+	}
 
-		sort.Slice(boxes, func(i, j int) bool {
-			return boxes[i].W*boxes[i].H > boxes[j].W*boxes[j].H
-		})
+	if len(errs) > 0 {
+		return nil, errs
 	}
+	if limitErr != nil {
+		return nil, limitErr
+	}
+
+	// by priority then descending by area
+	sort.SliceStable(boxes, func(i, j int) bool {
+		pi, pj := op.pieces[boxes[i]].Priority, op.pieces[boxes[j]].Priority
+		if pi != pj {
+			return pi > pj
+		}
+		return boxes[i].W*boxes[i].H > boxes[j].W*boxes[j].H
+	})
 	return
 }
 
 type FitReader map[string]io.Reader
 
-func (op *Op) matchboxes(strategyName string, strategy *pak.Base, boxes []*pak.Box) ([]float64, []*pak.Box, []*pak.Box, []FitReader) {
+func (op *Op) matchboxes(strategyName string, strategy *pak.Base, boxes []*pak.Box, pieces map[*pak.Box]*Piece) ([]float64, []*pak.Box, []*pak.Box, []FitReader, []Placement) {
 
 	var (
 		lenboxes  int
@@ -362,22 +589,24 @@ func (op *Op) matchboxes(strategyName string, strategy *pak.Base, boxes []*pak.B
 	)
 	inx, usedArea, vendoredArea, vendoredLength, boxesArea, boxesPerim := 0, 0.0, 0.0, 0.0, 0.0, 0.0
 	fnOutput := []FitReader{}
+	sheets := []sheet{}
+	layout := []Placement{}
 
 	lenboxes = len(boxes)
 
 	for lenboxes > 0 {
-		// shrink all aria
-		op.width -= op.topleftmargin
-		op.height -= op.topleftmargin
-		bin := pak.NewBin(op.width, op.height, strategy)
+		// boxes lay inside margins
+		rw, rh := op.room()
+		// last row and column need no gutter toward sheet edges
+		bin := pak.NewBin(rw+op.gutter, rh+op.gutter, strategy)
 		remaining = []*pak.Box{}
 		maxx, maxy := 0.0, 0.0
 		// partials metrics per cycle
-		vendoredAreaForInx, vendoredLengthForInx := 0.0, 0.0
+		vendoredAreaForInx, vendoredLengthForInx, boxesAreaForInx := 0.0, 0.0, 0.0
 		// pack boxes into bin
 		for _, box := range boxes {
 			// cutwidth acts like a padding enlarging boxes
-			if op.topleftmargin == 0.0 {
+			if op.margins.Top == 0.0 && op.margins.Left == 0.0 {
 				// all boxes touching top or left edges will need a half expand
 				if box.X == 0.0 && box.Y == 0.0 { // top left box
 					box.W -= op.cutwidth / 2
@@ -392,31 +621,44 @@ func (op *Op) matchboxes(strategyName string, strategy *pak.Base, boxes []*pak.B
 					box.X -= op.cutwidth / 2
 					box.Y -= op.cutwidth / 2
 				}
-			} else {
-				// no need to adjust W or H but X and Y
-				box.X += op.topleftmargin
-				box.Y += op.topleftmargin
 			}
 			if !bin.Insert(box) {
 				remaining = append(remaining, box)
 				// cannot insert skyp to next box
 				continue
 			}
+			// bin places boxes from its own corner; move them past margins
+			box.X += op.margins.Left
+			box.Y += op.margins.Top
+			box.W -= op.gutter
+			box.H -= op.gutter
 			done = append(done, box)
+			// blocks count by their pieces, not by room between them
+			for _, pl := range placements(pieces[box], box, inx+1) {
+				layout = append(layout, pl)
+				// bleed is printed then cut away; only trim is billed as used
+				w, h := pl.W, pl.H
+				if pl.Piece != nil {
+					w, h = w-2*pl.Piece.Bleed, h-2*pl.Piece.Bleed
+				}
+				boxesArea += (w * h)
+				boxesAreaForInx += (w * h)
+				boxesPerim += 2 * (w + h)
+			}
 
-			boxesArea += (box.W * box.H)
-			boxesPerim += 2 * (box.W + box.H)
-
-			if box.Y+box.H-op.topleftmargin > maxy {
-				maxy = box.Y + box.H - op.topleftmargin
+			if box.Y+box.H-op.margins.Top > maxy {
+				maxy = box.Y + box.H - op.margins.Top
 			}
-			if box.X+box.W-op.topleftmargin > maxx {
-				maxx = box.X + box.W - op.topleftmargin
+			if box.X+box.W-op.margins.Left > maxx {
+				maxx = box.X + box.W - op.margins.Left
 			}
 		}
-		// enlarge aria back
-		op.width += op.topleftmargin
-		op.height += op.topleftmargin
+		// a pass that placed nothing takes no sheet
+		if len(remaining) == lenboxes {
+			break
+		}
+		// margins are part of sheet
+		maxy += op.margins.Top + op.margins.Bottom
 
 		if op.tight {
 			maxx = op.width
@@ -439,7 +681,8 @@ func (op *Op) matchboxes(strategyName string, strategy *pak.Base, boxes []*pak.B
 
 		inx++
 
-		if len(remaining) == lenboxes {
+		// one sheet over limit is enough for telling
+		if op.limits.MaxSheets > 0 && inx > op.limits.MaxSheets {
 			break
 		}
 		lenboxes = len(remaining)
@@ -447,25 +690,120 @@ func (op *Op) matchboxes(strategyName string, strategy *pak.Base, boxes []*pak.B
 
 		if op.outname != "" {
 			// vendoredLength is a fraction associated with inx cycle from cummulative vendoredLength
-			func(inx int, boxes []*pak.Box, vendoredLengthForInx float64) {
-				fn := fmt.Sprintf("%s.%d.%s.svg", op.outname, inx, strategyName)
-
-				var s string
-				if op.outweb {
-					s = svg.StartWeb(op.width, vendoredLengthForInx+op.topleftmargin, op.plain)
-				} else {
-					s = svg.Start(op.width, vendoredLengthForInx+op.topleftmargin, op.unit, op.plain)
-				}
-				si, err := svg.Out(boxes, op.cutwidth, op.topleftmargin, op.width, op.unit, op.plain, op.showDim, op.outline)
-				if err != nil {
-					return
-				}
-				s += svg.End(si)
-				fnOutput = append(fnOutput, FitReader{fn: strings.NewReader(s)})
-			}(inx, bin.Boxes[:], vendoredLengthForInx)
+			sheets = append(sheets, sheet{bin.Boxes[:], pieces, vendoredLengthForInx, boxesAreaForInx})
 		}
 	}
-	return []float64{usedArea, vendoredArea, vendoredLength, boxesArea, boxesPerim, float64(inx)}, done, remaining, fnOutput
+	// sheets are rendered at the end as each one needs to know how many they are
+	for i, sh := range sheets {
+		out, err := op.render(strategyName, i+1, len(sheets), sh)
+		if err != nil {
+			continue
+		}
+		fnOutput = append(fnOutput, out)
+	}
+	return []float64{usedArea, vendoredArea, vendoredLength, boxesArea, boxesPerim, float64(inx)}, done, remaining, fnOutput, layout
+}
+
+// sheet keeps what is needed for rendering a packed big box
+type sheet struct {
+	boxes     []*pak.Box
+	pieces    map[*pak.Box]*Piece
+	length    float64
+	boxesArea float64
+}
+
+// render produces svg of a sheet; inx is 1 based position of sheet out of total
+func (op *Op) render(strategyName string, inx, total int, sh sheet) (FitReader, error) {
+	fn := fmt.Sprintf("%s.%d.%s.svg", op.outname, inx, strategyName)
+
+	w, h := op.width, sh.length
+	th := 0.0
+	if op.titleBlock || op.legend {
+		th = svg.TitleBlockHeight(w)
+	}
+	// room on top and left for overall dimensions
+	pad := 0.0
+	fr := svg.FontRange{Min: op.minFont, Max: op.maxFont}
+	if op.showDim {
+		pad = 3 * fr.Min
+		if pad < w/40 {
+			pad = math.Min(w/40, 3*fr.Max)
+		}
+	}
+
+	var s string
+	if op.outweb {
+		s = svg.StartWebAt(-pad, -pad, w+pad, h+th+pad, op.plain)
+	} else {
+		unit, scale := op.lengthUnit.svgUnit()
+		s = svg.StartAt(-pad, -pad, w+pad, h+th+pad, unit, scale, op.plain)
+	}
+	// pieces filling a sheet are drawn one by one
+	sh = op.opened(sh)
+	var si string
+	// fills drawn, for legend
+	var fills []string
+	if op.impose {
+		// printed sheet shows artwork alone
+		if aa := op.arts(sh); len(aa) > 0 {
+			si = svg.Artwork(aa, op.plain)
+		}
+	} else {
+		var err error
+		si, err = svg.Out(sh.boxes, op.cutwidth, op.margins.Top, op.margins.Left, op.width, op.plain, op.outline)
+		if err != nil {
+			return nil, err
+		}
+		edges := op.bandedEdges(sh)
+		if len(edges) > 0 {
+			si += svg.Banding(edges, op.width, op.plain)
+		}
+		if rr := op.rounds(sh); len(rr) > 0 {
+			si += svg.Rounds(rr, op.plain, op.outline)
+		}
+		if bleed, trim := op.bleedBoxes(sh); len(bleed) > 0 {
+			si += svg.Bleed(bleed, trim, op.plain)
+		}
+		zones, marks := op.panelMarks(sh)
+		if len(marks) > 0 {
+			si += svg.Panels(zones, marks, op.plain)
+		}
+		// outlined blocks are not filled
+		filled := sh.boxes
+		if op.outline {
+			filled = nil
+		}
+		fills = svg.Fills(filled, op.margins.Top, op.margins.Left, len(edges) > 0, len(zones) > 0)
+	}
+	if crop, reg := op.cropMarks(sh), op.regMarks(inx, op.printedLength(sh.boxes)); len(crop)+len(reg) > 0 {
+		si += svg.Marks(crop, svgMarks(reg), op.plain)
+	}
+	if op.showDim {
+		si += svg.ArrowDefs()
+		si += svg.Dimensions(sh.boxes, op.cutwidth, op.width, fr, op.plain)
+		si += svg.SheetDimensions(w, h, pad, op.unit, fr, op.plain)
+	}
+	if th > 0 {
+		utilisation := 0.0
+		if sh.length > 0 {
+			utilisation = sh.boxesArea * 100 / (w * sh.length)
+		}
+		si += svg.TitleBlock(h, w, th, svg.TitleInfo{
+			Job:         op.job,
+			Material:    op.material,
+			Sheet:       inx,
+			Sheets:      total,
+			W:           w,
+			H:           sh.length,
+			Unit:        op.unit,
+			Utilisation: utilisation,
+			Date:        time.Now().Format("2006-01-02"),
+			Fills:       fills,
+		}, op.titleBlock, op.legend, op.plain)
+	}
+	s += svg.End(si)
+
+	return FitReader{fn: strings.NewReader(s)}, nil
 }
 
 //go:generate json_snake_case -type=Report
@@ -479,9 +817,33 @@ type Report struct {
 	LostArea           float64
 	ProcentArea        float64
 	BoxesPerim         float64
-	Price              float64
+	Banding            map[string]float64
+	Price              Money
+	PriceLines         Bill
+	Cost               Money
+	Costs              Bill
+	Margin             float64
+	Warnings           []string
+	Currency           string
+	VATRate            float64
+	Net                Money
+	VAT                Money
+	Gross              Money
 	UnfitLen           int
 	UnfitCode          string
 	FitCode            string
+	Unfit              []Unfit
 	NumSheetUsed       float64
+	Layout             []Placement
+	Registration       []RegMark
+	Capacity           *Capacity
+}
+
+// BandingLength sums edge tape of all kinds
+func (rep *Report) BandingLength() float64 {
+	l := 0.0
+	for _, tl := range rep.Banding {
+		l += tl
+	}
+	return l
 }
@@ -2,6 +2,15 @@ package packong
 
 import "github.com/innermond/pak"
 
+// NumPermutations tells how many arrangements Permutations makes of n boxes
+func NumPermutations(n int) float64 {
+	p := 1.0
+	for i := 2; i <= n; i++ {
+		p *= float64(i)
+	}
+	return p
+}
+
 // Permutations give all combinations of a slice of boxes
 // caveat: it is holding all in memory
 func Permutations(arr []*pak.Box) [][]*pak.Box {
@@ -0,0 +1,40 @@
+package packong
+
+// Piece is a box as it was asked for, before being expanded by cut width
+type Piece struct {
+	ID    int     `json:"id"`
+	Label string  `json:"label"`
+	W     float64 `json:"w"`
+	H     float64 `json:"h"`
+	// Material is informative, Priority orders packing
+	Material string `json:"material,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+	// edges having tape, nil when none
+	Banding *Banding `json:"banding,omitempty"`
+	// how much print extends past trim edges on every side
+	Bleed float64 `json:"bleed,omitempty"`
+	// part of a piece too large for sheet, nil when whole
+	Panel *Panel `json:"panel,omitempty"`
+	// outline within w by h and corner radius of a rounded rectangle
+	Form   string  `json:"form,omitempty"`
+	Radius float64 `json:"radius,omitempty"`
+	// artwork file printed on piece
+	Art string `json:"art,omitempty"`
+
+	// pieces a staggered or filling block stands for; the block is no piece itself
+	stagger *stagger
+}
+
+// Placement tells where a piece landed after packing
+type Placement struct {
+	Piece *Piece `json:"piece"`
+	// sheet number, 1 based
+	Sheet   int     `json:"sheet"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	W       float64 `json:"w"`
+	H       float64 `json:"h"`
+	Rotated bool    `json:"rotated"`
+	// degrees a nested outline is turned by
+	Angle float64 `json:"angle,omitempty"`
+}
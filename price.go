@@ -0,0 +1,85 @@
+package packong
+
+// names of price breakdown lines
+const (
+	LineMaterial = "material"
+	LineWaste    = "waste"
+	LineCutting  = "cutting"
+	LineBanding  = "banding"
+	LineLabour   = "labour"
+	LineTravel   = "travel"
+	LineMarkup   = "markup"
+)
+
+// PriceLine is an item of a price breakdown
+type PriceLine struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+	// tells how amount was reached, when that is not obvious
+	Note string `json:"note,omitempty"`
+}
+
+// Breakdown is an itemised price
+type Breakdown []PriceLine
+
+// Total sums all lines
+func (b Breakdown) Total() float64 {
+	t := 0.0
+	for _, l := range b {
+		t += l.Amount
+	}
+	return t
+}
+
+// PriceModel turns metrics of a report into an itemised price
+type PriceModel interface {
+	Price(rep *Report) Breakdown
+}
+
+// StandardPrice is the default price model;
+// areas are priced per square meter, perimeter and banding per linear meter
+type StandardPrice struct {
+	// Mu material used, Ml material lost, Pp perimeter (cuts), Pd travel,
+	// Pb edge banding; labour is a cost, see CostPlus
+	Mu, Ml, Pp, Pd, Pb float64
+	// Markup is a percent added on top of all other lines
+	Markup float64
+	// Greedy prices lost material as used material
+	Greedy bool
+}
+
+// Price implements PriceModel
+func (sp StandardPrice) Price(rep *Report) Breakdown {
+	ml := sp.Ml
+	if sp.Greedy {
+		ml = sp.Mu
+	}
+	b := Breakdown{
+		{Name: LineMaterial, Amount: rep.BoxesArea * sp.Mu},
+		{Name: LineWaste, Amount: rep.LostArea * ml},
+		{Name: LineCutting, Amount: rep.BoxesPerim * sp.Pp},
+		{Name: LineBanding, Amount: rep.BandingLength() * sp.Pb},
+		{Name: LineTravel, Amount: sp.Pd},
+	}
+	return append(b, PriceLine{Name: LineMarkup, Amount: b.Total() * sp.Markup / 100})
+}
+
+// PriceModel replaces the standard price model
+func (op *Op) PriceModel(pm PriceModel) *Op {
+	op.model = pm
+	return op
+}
+
+func (op *Op) priceModel() PriceModel {
+	if op.model != nil {
+		return op.model
+	}
+	if op.costplus {
+		return op.costs()
+	}
+	sp := StandardPrice{Mu: op.mu, Ml: op.ml, Pp: op.pp, Pd: op.pd, Pb: op.pb, Greedy: op.greedy}
+	if op.list != nil {
+		return TieredPrice{sp, op.list, op.material, op.group}
+	}
+	return sp
+}
@@ -0,0 +1,148 @@
+package packong
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTieredPrice(t *testing.T) {
+	pl, err := LoadPriceList(strings.NewReader(`{
+		"tiers": {"mdf18": [{"from": 20, "rate": 10}, {"from": 0, "rate": 15}, {"from": 5, "rate": 12}]},
+		"minimum": {"*": 50},
+		"discounts": {"reseller": {"percent": 10}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type test struct {
+		area          float64
+		group         string
+		price, tier   float64
+		minimumCharge bool
+	}
+	tt := []test{
+		{2, "", 50, 15, true},
+		{10, "", 120, 12, false},
+		{10, "reseller", 108, 12, false},
+		{30, "reseller", 270, 10, false},
+	}
+	for _, tc := range tt {
+		tp := TieredPrice{List: pl, Material: "mdf18", Group: tc.group}
+		b := tp.Price(&Report{BoxesArea: tc.area})
+		if math.Abs(b.Total()-tc.price) > 1e-9 {
+			t.Errorf("area %v group %q: got price %v, expected %v", tc.area, tc.group, b.Total(), tc.price)
+		}
+		if b[0].Amount != tc.area*tc.tier {
+			t.Errorf("area %v: got material %v, expected rate %v", tc.area, b[0].Amount, tc.tier)
+		}
+		if got := b[len(b)-1].Name == LineMinimum; got != tc.minimumCharge {
+			t.Errorf("area %v: minimum charge applied %v, expected %v", tc.area, got, tc.minimumCharge)
+		}
+	}
+}
+
+func TestStandardPrice(t *testing.T) {
+	rep := &Report{BoxesArea: 2, LostArea: 0.5, BoxesPerim: 6}
+	op := NewOp(1000, 2000, nil, "mm").Price(15, 5, 0.25, 15).Labour(3.5)
+	// as old cli priced: area, waste, cuts and travel; labour is a cost, not priced
+	want := map[string]float64{
+		LineMaterial: 2 * 15,
+		LineWaste:    0.5 * 5,
+		LineCutting:  6 * 0.25,
+		LineBanding:  0,
+		LineTravel:   15,
+		LineMarkup:   0,
+	}
+	b := op.priceModel().Price(rep)
+	if len(b) != len(want) {
+		t.Fatalf("got %d lines, expected %d", len(b), len(want))
+	}
+	for _, l := range b {
+		if math.Abs(l.Amount-want[l.Name]) > 1e-9 {
+			t.Errorf("line %s: got %v, expected %v", l.Name, l.Amount, want[l.Name])
+		}
+	}
+	if total := b.Total(); math.Abs(total-49) > 1e-9 {
+		t.Errorf("got total %v, expected 49", total)
+	}
+
+	// greedy prices waste as used material
+	b = op.Greedy(true).priceModel().Price(rep)
+	if b[1].Name != LineWaste || b[1].Amount != 0.5*15 {
+		t.Errorf("got %+v, expected waste priced at 7.5", b[1])
+	}
+}
+
+func TestMoney(t *testing.T) {
+	type test struct {
+		v    float64
+		r    Rounding
+		want string
+	}
+	tt := []test{
+		{0.285, HalfUp, "0.29"},
+		{-0.285, HalfUp, "-0.29"},
+		{0.125, HalfEven, "0.12"},
+		{0.135, HalfEven, "0.14"},
+		{1.999, Down, "1.99"},
+		{1.001, Up, "1.01"},
+		{-0.05, HalfUp, "-0.05"},
+	}
+	for _, tc := range tt {
+		if got := tc.r.Money(tc.v, 2).String(); got != tc.want {
+			t.Errorf("%v rounded by %d: got %s, expected %s", tc.v, tc.r, got, tc.want)
+		}
+	}
+
+	for _, s := range []string{"12.34", "-0.05", "7.00", "7", "0.125"} {
+		m, err := ParseMoney(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.String() != s {
+			t.Errorf("got %s, expected %s", m, s)
+		}
+	}
+	for _, s := range []string{"1.2345", "--5", "+-5", "1.-5", "", "-"} {
+		if _, err := ParseMoney(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+	var j struct{ M Money }
+	if err := json.Unmarshal([]byte(`{"M":null}`), &j); err != nil || j.M.Sign() != 0 {
+		t.Errorf("got %v %v, expected null read as zero", j.M, err)
+	}
+}
+
+func TestBillAddsUp(t *testing.T) {
+	b := Breakdown{{Name: "a", Amount: 1.005}, {Name: "b", Amount: 2.335}, {Name: "c", Amount: 0.333}}
+	rp := RoundingPolicy{Step: Money{50, 2}}
+	bill := rp.Bill(b, 1, 2)
+	if bill.Total().at(2)%50 != 0 {
+		t.Errorf("total %s is not a multiple of step", bill.Total())
+	}
+	if bill[len(bill)-1].Name != LineRounding {
+		t.Errorf("expected a rounding line, got %v", bill)
+	}
+}
+
+func TestDecimals(t *testing.T) {
+	// yen has no minor unit, dinar has thousandths
+	b := Breakdown{{Name: "a", Amount: 10.2}, {Name: "b", Amount: 0.3456}}
+	for _, tc := range []struct {
+		currency, want string
+	}{{"JPY", "10"}, {"eur", "10.55"}, {"kwd", "10.546"}} {
+		total := RoundingPolicy{}.Bill(b, 1, Decimals(tc.currency)).Total()
+		if total.String() != tc.want {
+			t.Errorf("%s: got %s, expected %s", tc.currency, total, tc.want)
+		}
+	}
+	// a step written with fewer decimals than currency's
+	step, _ := ParseMoney("0.5")
+	if got := (RoundingPolicy{Step: step}).Bill(b, 1, 2).Total().String(); got != "10.50" {
+		t.Errorf("got %s, expected 10.50", got)
+	}
+}
@@ -5,20 +5,35 @@ package packong
 import "encoding/json"
 
 type ReportJSON struct {
-	WiningStrategyName string  `json:"wining_strategy_name"`
-	BoxesArea          float64 `json:"boxes_area"`
-	UsedArea           float64 `json:"used_area"`
-	VendoredArea       float64 `json:"vendored_area"`
-	VendoredLength     float64 `json:"vendored_length"`
-	VendoredWidth      float64 `json:"vendored_width"`
-	LostArea           float64 `json:"lost_area"`
-	ProcentArea        float64 `json:"procent_area"`
-	BoxesPerim         float64 `json:"boxes_perim"`
-	Price              float64 `json:"price"`
-	UnfitLen           int     `json:"unfit_len"`
-	UnfitCode          string  `json:"unfit_code"`
-	FitCode            string  `json:"fit_code"`
-	NumSheetUsed       float64 `json:"num_sheet_used"`
+	WiningStrategyName string             `json:"wining_strategy_name"`
+	BoxesArea          float64            `json:"boxes_area"`
+	UsedArea           float64            `json:"used_area"`
+	VendoredArea       float64            `json:"vendored_area"`
+	VendoredLength     float64            `json:"vendored_length"`
+	VendoredWidth      float64            `json:"vendored_width"`
+	LostArea           float64            `json:"lost_area"`
+	ProcentArea        float64            `json:"procent_area"`
+	BoxesPerim         float64            `json:"boxes_perim"`
+	Banding            map[string]float64 `json:"banding"`
+	Price              Money              `json:"price"`
+	PriceLines         Bill               `json:"price_lines"`
+	Cost               Money              `json:"cost"`
+	Costs              Bill               `json:"costs"`
+	Margin             float64            `json:"margin"`
+	Warnings           []string           `json:"warnings"`
+	Currency           string             `json:"currency"`
+	VATRate            float64            `json:"vat_rate"`
+	Net                Money              `json:"net"`
+	VAT                Money              `json:"vat"`
+	Gross              Money              `json:"gross"`
+	UnfitLen           int                `json:"unfit_len"`
+	UnfitCode          string             `json:"unfit_code"`
+	FitCode            string             `json:"fit_code"`
+	Unfit              []Unfit            `json:"unfit"`
+	NumSheetUsed       float64            `json:"num_sheet_used"`
+	Layout             []Placement        `json:"layout"`
+	Registration       []RegMark          `json:"registration"`
+	Capacity           *Capacity          `json:"capacity"`
 }
 
 func (m Report) MarshalJSON() ([]byte, error) {
@@ -37,10 +52,25 @@ func NewReportJSON(m *Report) *ReportJSON {
 		LostArea:           m.LostArea,
 		ProcentArea:        m.ProcentArea,
 		BoxesPerim:         m.BoxesPerim,
+		Banding:            m.Banding,
 		Price:              m.Price,
+		PriceLines:         m.PriceLines,
+		Cost:               m.Cost,
+		Costs:              m.Costs,
+		Margin:             m.Margin,
+		Warnings:           m.Warnings,
+		Currency:           m.Currency,
+		VATRate:            m.VATRate,
+		Net:                m.Net,
+		VAT:                m.VAT,
+		Gross:              m.Gross,
 		UnfitLen:           m.UnfitLen,
 		UnfitCode:          m.UnfitCode,
 		FitCode:            m.FitCode,
+		Unfit:              m.Unfit,
 		NumSheetUsed:       m.NumSheetUsed,
+		Layout:             m.Layout,
+		Registration:       m.Registration,
+		Capacity:           m.Capacity,
 	}
 }
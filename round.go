@@ -0,0 +1,193 @@
+package packong
+
+import (
+	"math"
+
+	"github.com/innermond/packong/internal/svg"
+	"github.com/innermond/pak"
+)
+
+// forms of pieces
+const (
+	FormRect    = ""
+	FormCircle  = "circle"
+	FormEllipse = "ellipse"
+)
+
+// staggered rows are closer than their pitch, by how high an equilateral triangle is
+var rowPitch = math.Sqrt(3) / 2
+
+// stagger holds round pieces laid in rows, every other row shifted by half a pitch,
+// so they sink into hollows left by their neighbours, or rectangles filling a sheet
+type stagger struct {
+	pieces []*Piece
+	// top left corners of pieces' boxes inside block, as block is not rotated
+	at [][2]float64
+	// pieces turned by a quarter inside block; nil when none is
+	turned []bool
+	// pieces' boxes, bleed and cut width included
+	w, h float64
+}
+
+// block is an arrangement of pieces as big as w by h
+type block struct {
+	w, h   float64
+	at     [][2]float64
+	turned []bool
+}
+
+// arrange lays rows of cols pieces, each w by h spacing included; short makes
+// every shifted row one piece shorter so block needs no half pitch more
+func arrange(cols, rows int, short bool, w, h float64, qty int) block {
+	bl := block{w: float64(cols) * w, h: h + float64(rows-1)*h*rowPitch}
+	if !short {
+		bl.w += w / 2
+	}
+	for r := 0; r < rows && len(bl.at) < qty; r++ {
+		x, n := 0.0, cols
+		if r%2 == 1 {
+			x = w / 2
+			if short {
+				n--
+			}
+		}
+		for c := 0; c < n && len(bl.at) < qty; c++ {
+			bl.at = append(bl.at, [2]float64{x + float64(c)*w, float64(r) * h * rowPitch})
+		}
+	}
+	return bl
+}
+
+// staggered groups qty round pieces, each w by h spacing included, into blocks
+// taking less room than a square grid would; pieces left out go one by one
+func (op *Op) staggered(w, h float64, qty int) []block {
+	rw, rh := op.room()
+	// blocks are packed like boxes, gutter included
+	rw, rh = rw+op.gutter, rh+op.gutter
+	// rows shifted by half a pitch take one piece less unless block is wider by that half
+	cols, shortCols := int((rw-w/2)/w), int(rw/w)
+	maxRows := 1 + int((rh-h)/(h*rowPitch))
+	if shortCols < 2 || maxRows < 2 {
+		return nil
+	}
+
+	// smallest area a square grid holds n pieces in
+	grid := func(n int) float64 {
+		best := math.Inf(1)
+		for c := 1; c <= shortCols; c++ {
+			rows := (n + c - 1) / c
+			best = math.Min(best, float64(c*rows)*w*h)
+		}
+		return best
+	}
+
+	var blocks []block
+	for qty > 2 {
+		var (
+			best    block
+			perArea = math.Inf(1)
+		)
+		for c := 2; c <= shortCols; c++ {
+			for _, short := range []bool{false, true} {
+				if !short && c > cols {
+					continue
+				}
+				// rows holding all pieces left, as many as sheet takes
+				rows, held := 0, 0
+				for held < qty && rows < maxRows {
+					if rows%2 == 1 && short {
+						held += c - 1
+					} else {
+						held += c
+					}
+					rows++
+				}
+				if rows < 2 {
+					continue
+				}
+				bl := arrange(c, rows, short, w, h, qty)
+				if a := bl.w * bl.h / float64(len(bl.at)); a < perArea {
+					best, perArea = bl, a
+				}
+			}
+		}
+		if len(best.at) == 0 || best.w*best.h >= grid(len(best.at)) {
+			break
+		}
+		blocks = append(blocks, best)
+		qty -= len(best.at)
+	}
+	return blocks
+}
+
+// placements tells where pieces of a packed box landed; a staggered block gives all its pieces
+func placements(pc *Piece, box *pak.Box, sheet int) []Placement {
+	if pc == nil || pc.stagger == nil {
+		return []Placement{{
+			Piece:   pc,
+			Sheet:   sheet,
+			X:       box.X,
+			Y:       box.Y,
+			W:       box.W,
+			H:       box.H,
+			Rotated: box.Rotated,
+		}}
+	}
+	st := pc.stagger
+	pp := []Placement{}
+	for i, m := range st.pieces {
+		x, y, w, h := st.at[i][0], st.at[i][1], st.w, st.h
+		turned := st.turned != nil && st.turned[i]
+		if turned {
+			w, h = h, w
+		}
+		if box.Rotated {
+			x, y, w, h = y, x, h, w
+		}
+		pp = append(pp, Placement{
+			Piece:   m,
+			Sheet:   sheet,
+			X:       box.X + x,
+			Y:       box.Y + y,
+			W:       w,
+			H:       h,
+			Rotated: box.Rotated != turned,
+		})
+	}
+	return pp
+}
+
+// members gives pieces a box stands for
+func (p *Piece) members() []*Piece {
+	if p.stagger != nil {
+		return p.stagger.pieces
+	}
+	return []*Piece{p}
+}
+
+// rounds gives outlines of pieces other than plain rectangles
+func (op *Op) rounds(sh sheet) []svg.Round {
+	rr := []svg.Round{}
+	for _, box := range sh.boxes {
+		for _, pl := range placements(sh.pieces[box], box, 0) {
+			pc := pl.Piece
+			if pc == nil || pc.Panel != nil || pc.Form == FormRect && pc.Radius == 0 {
+				continue
+			}
+			w, h := pc.W, pc.H
+			if pl.Rotated {
+				w, h = h, w
+			}
+			rr = append(rr, svg.Round{
+				X:       pl.X + pc.Bleed,
+				Y:       pl.Y + pc.Bleed,
+				W:       w,
+				H:       h,
+				R:       pc.Radius,
+				Ellipse: pc.Form != FormRect,
+				Bleed:   pc.Bleed,
+			})
+		}
+	}
+	return rr
+}
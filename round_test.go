@@ -0,0 +1,55 @@
+package packong
+
+import (
+	"math"
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestStaggered(t *testing.T) {
+	fit := func(dd ...string) *Report {
+		op := NewOp(1000, 50000, dd, "mm").VendorSellInt(false)
+		boxes, err := op.BoxesFromString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rep, _, err := op.Fit([][]*pak.Box{boxes}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rep
+	}
+
+	round, square := fit("d80x50"), fit("80x80x50")
+	if len(round.Layout) != 50 {
+		t.Fatalf("got %d circles placed, expected 50", len(round.Layout))
+	}
+	if round.VendoredLength >= square.VendoredLength {
+		t.Errorf("staggered circles take %vm, square grid %vm", round.VendoredLength, square.VendoredLength)
+	}
+	// area of pieces, their boxes 80 by 80, not of blocks holding them
+	if want := 50 * 0.08 * 0.08; math.Abs(round.BoxesArea-want) > 1e-9 {
+		t.Errorf("got boxes area %v, expected %v", round.BoxesArea, want)
+	}
+	for i, a := range round.Layout {
+		if a.Piece.Form != FormCircle || a.W != 80 || a.H != 80 {
+			t.Fatalf("got %+v, expected a 80 circle", a)
+		}
+		for _, b := range round.Layout[i+1:] {
+			if d := math.Hypot(a.X-b.X, a.Y-b.Y); d < 80-1e-9 {
+				t.Errorf("circles %d and %d overlap, %v apart", a.Piece.ID, b.Piece.ID, d)
+			}
+		}
+	}
+
+	// a few circles are no better staggered
+	op := NewOp(1000, 50000, []string{"d80x4"}, "mm")
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boxes) != 4 {
+		t.Errorf("got %d boxes, expected 4", len(boxes))
+	}
+}
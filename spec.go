@@ -0,0 +1,285 @@
+package packong
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PieceSpec is a dimension entry once parsed.
+//
+// An entry starts with "wxh[xqty[xrotate[xbanding]]]", the compact form,
+// which may be followed by named attributes such as
+//
+//	500x300 qty=4 rotate=no label="door" material=mdf18 priority=1 band=tb:0.8:abs bleed=3 radius=5 art=door.png
+//
+// Named attributes win over the compact ones. A compact form starting with d
+// is a circle given by its diameter, as d80x50 for 50 circles; one starting
+// with e is an ellipse, as e80x50x20.
+type PieceSpec struct {
+	W, H     float64
+	Qty      int
+	Rotate   bool
+	Label    string
+	Material string
+	// pieces having higher priority are packed first
+	Priority int
+	Banding  *Banding
+	// print past trim edges; zero takes op's bleed
+	Bleed float64
+	// outline within w by h: FormRect, FormCircle or FormEllipse
+	Form string
+	// corner radius of a rounded rectangle
+	Radius float64
+	// artwork file printed on piece
+	Art string
+}
+
+// ParseSpec reads a dimension entry; when it fails error is a ValidationError
+// listing every problem of entry
+func ParseSpec(s string) (PieceSpec, error) {
+	ps := PieceSpec{Qty: 1, Rotate: true}
+
+	var errs ValidationError
+	bad := func(field, format string, a ...interface{}) {
+		errs = append(errs, Invalid{Value: s, Field: field, Reason: fmt.Sprintf(format, a...)})
+	}
+
+	tokens, err := fields(s)
+	if err != nil {
+		bad("entry", "has an unterminated quote")
+		return ps, errs
+	}
+	compact, attrs := []string{}, []string{}
+	for _, t := range tokens {
+		if strings.Contains(t, "=") {
+			attrs = append(attrs, t)
+			continue
+		}
+		// a compact form may hold spaces, like 24 1/2x36
+		compact = append(compact, t)
+	}
+
+	joined := strings.Join(compact, " ")
+	switch {
+	case strings.HasPrefix(joined, "d"):
+		// a circle gives its diameter once
+		ps.Form = FormCircle
+		joined = strings.TrimPrefix(joined, "d")
+		joined = strings.SplitN(joined, "x", 2)[0] + "x" + joined
+	case strings.HasPrefix(joined, "e"):
+		ps.Form = FormEllipse
+		joined = strings.TrimPrefix(joined, "e")
+	}
+	d := strings.Split(joined, "x")
+	if len(d) < 2 || len(d) > 5 {
+		bad("entry", "needs \"wxh[xqty[xrotate[xbanding]]]\", \"d[xqty]\" or \"ewxh[xqty[xrotate]]\"")
+		return ps, errs
+	}
+	if ps.W, err = parseLength(d[0]); err != nil {
+		bad("width", "%q is not a length", d[0])
+	}
+	if ps.H, err = parseLength(d[1]); err != nil {
+		bad("height", "%q is not a length", d[1])
+	}
+	if len(d) > 2 {
+		if ps.Qty, err = strconv.Atoi(d[2]); err != nil {
+			bad("qty", "%q is not a whole number", d[2])
+		}
+	}
+	if len(d) > 3 {
+		if ps.Rotate, err = parseYesNo(d[3]); err != nil {
+			bad("rotate", "%q is not yes or no", d[3])
+		}
+	}
+	if len(d) > 4 {
+		if ps.Banding, err = parseBanding(d[4]); err != nil {
+			bad("band", "%v", err)
+		}
+	}
+
+	for _, a := range attrs {
+		kv := strings.SplitN(a, "=", 2)
+		k, v := kv[0], kv[1]
+		switch k {
+		case "qty":
+			if ps.Qty, err = strconv.Atoi(v); err != nil {
+				bad(k, "%q is not a whole number", v)
+			}
+		case "rotate":
+			if ps.Rotate, err = parseYesNo(v); err != nil {
+				bad(k, "%q is not yes or no", v)
+			}
+		case "label":
+			if ps.Label, err = unquote(v); err != nil {
+				bad(k, "%s is badly quoted", v)
+			}
+		case "material":
+			if ps.Material, err = unquote(v); err != nil {
+				bad(k, "%s is badly quoted", v)
+			}
+		case "priority":
+			if ps.Priority, err = strconv.Atoi(v); err != nil {
+				bad(k, "%q is not a whole number", v)
+			}
+		case "band":
+			if ps.Banding, err = parseBanding(v); err != nil {
+				bad(k, "%v", err)
+			}
+		case "bleed":
+			if ps.Bleed, err = parseLength(v); err != nil || ps.Bleed < 0 {
+				bad(k, "%q is not a length", v)
+			}
+		case "form":
+			switch v {
+			case "rect":
+				ps.Form = FormRect
+			case FormCircle, FormEllipse:
+				ps.Form = v
+			default:
+				bad(k, "%q is neither rect, circle nor ellipse", v)
+			}
+		case "art":
+			if ps.Art, err = unquote(v); err != nil {
+				bad(k, "%s is badly quoted", v)
+			} else if !IsArtwork(ps.Art) {
+				bad(k, "%q is neither png, jpeg nor svg", ps.Art)
+			}
+		case "radius":
+			if ps.Radius, err = parseLength(v); err != nil || ps.Radius < 0 {
+				bad(k, "%q is not a length", v)
+			}
+		default:
+			bad(k, "is an unknown attribute")
+		}
+	}
+
+	if ps.W <= 0 && !errs.has(0, "width") {
+		bad("width", "must be greater than zero; received %v", ps.W)
+	}
+	if ps.H <= 0 && !errs.has(0, "height") {
+		bad("height", "must be greater than zero; received %v", ps.H)
+	}
+	if ps.Qty < 1 && !errs.has(0, "qty") {
+		bad("qty", "must be greater than zero; received %d", ps.Qty)
+	}
+	if ps.Form == FormCircle && ps.W != ps.H && len(errs) == 0 {
+		bad("form", "circle needs width equal to height; received %vx%v", ps.W, ps.H)
+	}
+	if ps.Form != FormRect && ps.Banding != nil {
+		bad("band", "needs straight edges; %s has none", ps.Form)
+	}
+	if ps.Radius > 0 {
+		if ps.Form != FormRect {
+			bad("radius", "rounds corners of rectangles only")
+		} else if 2*ps.Radius > math.Min(ps.W, ps.H) && len(errs) == 0 {
+			bad("radius", "%v is more than half of %vx%v", ps.Radius, ps.W, ps.H)
+		}
+	}
+	if len(errs) > 0 {
+		return ps, errs
+	}
+	return ps, nil
+}
+
+// String writes spec using named attributes; defaults are left out
+func (ps PieceSpec) String() string {
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	ss := []string{f(ps.W) + "x" + f(ps.H)}
+	switch ps.Form {
+	case FormCircle:
+		ss[0] = "d" + f(ps.W)
+	case FormEllipse:
+		ss[0] = "e" + ss[0]
+	}
+	if ps.Qty != 1 {
+		ss = append(ss, "qty="+strconv.Itoa(ps.Qty))
+	}
+	if !ps.Rotate {
+		ss = append(ss, "rotate=no")
+	}
+	if ps.Label != "" {
+		ss = append(ss, "label="+quote(ps.Label))
+	}
+	if ps.Material != "" {
+		ss = append(ss, "material="+quote(ps.Material))
+	}
+	if ps.Priority != 0 {
+		ss = append(ss, "priority="+strconv.Itoa(ps.Priority))
+	}
+	if ps.Banding != nil {
+		ss = append(ss, "band="+ps.Banding.String())
+	}
+	if ps.Bleed != 0 {
+		ss = append(ss, "bleed="+f(ps.Bleed))
+	}
+	if ps.Radius != 0 {
+		ss = append(ss, "radius="+f(ps.Radius))
+	}
+	if ps.Art != "" {
+		ss = append(ss, "art="+quote(ps.Art))
+	}
+	return strings.Join(ss, " ")
+}
+
+// fields splits s by white space keeping double quoted text together
+func fields(s string) ([]string, error) {
+	var (
+		tokens  []string
+		b       strings.Builder
+		quoted  bool
+		escaped bool
+	)
+	for _, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case !quoted && (c == ' ' || c == '\t'):
+			if b.Len() > 0 {
+				tokens = append(tokens, b.String())
+				b.Reset()
+			}
+			continue
+		}
+		b.WriteRune(c)
+	}
+	if quoted {
+		return nil, fmt.Errorf("entry %q has an unterminated quote", s)
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+	return tokens, nil
+}
+
+func unquote(v string) (string, error) {
+	if strings.HasPrefix(v, `"`) {
+		return strconv.Unquote(v)
+	}
+	return v, nil
+}
+
+func quote(v string) string {
+	if strings.ContainsAny(v, " \t\"\\=") || v == "" {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// parseYesNo reads a boolean also written as yes or no
+func parseYesNo(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "yes", "y":
+		return true, nil
+	case "no", "n":
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
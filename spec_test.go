@@ -0,0 +1,53 @@
+package packong
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSpec(t *testing.T) {
+	type test struct {
+		s    string
+		want PieceSpec
+	}
+	tt := []test{
+		{"500x300", PieceSpec{W: 500, H: 300, Qty: 1, Rotate: true}},
+		{"500x300x4xfalse", PieceSpec{W: 500, H: 300, Qty: 4}},
+		{"24 1/2x36x3", PieceSpec{W: 24.5, H: 36, Qty: 3, Rotate: true}},
+		{`500x300 qty=4 rotate=no label="kitchen door" material=mdf18 priority=1`,
+			PieceSpec{W: 500, H: 300, Qty: 4, Label: "kitchen door", Material: "mdf18", Priority: 1}},
+		{"500x300x2 qty=3 band=tb:0.8:abs",
+			PieceSpec{W: 500, H: 300, Qty: 3, Rotate: true, Banding: &Banding{Top: true, Bottom: true, Thickness: 0.8, Tape: "abs"}}},
+		{"500x300 bleed=1/8", PieceSpec{W: 500, H: 300, Qty: 1, Rotate: true, Bleed: 0.125}},
+		{"d80x50", PieceSpec{W: 80, H: 80, Qty: 50, Rotate: true, Form: FormCircle}},
+		{"e80x50x20xno", PieceSpec{W: 80, H: 50, Qty: 20, Form: FormEllipse}},
+		{"50x30 radius=5", PieceSpec{W: 50, H: 30, Qty: 1, Rotate: true, Radius: 5}},
+		{`50x30 art="art/logo 2.png"`, PieceSpec{W: 50, H: 30, Qty: 1, Rotate: true, Art: "art/logo 2.png"}},
+	}
+	for _, tc := range tt {
+		got, err := ParseSpec(tc.s)
+		if err != nil {
+			t.Errorf("%q: %v", tc.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: got %+v, expected %+v", tc.s, got, tc.want)
+		}
+		// formatted spec reads back the same
+		again, err := ParseSpec(got.String())
+		if err != nil {
+			t.Errorf("%q: %v", got.String(), err)
+			continue
+		}
+		if !reflect.DeepEqual(again, got) {
+			t.Errorf("%q: got %+v, expected %+v", got.String(), again, got)
+		}
+	}
+
+	for _, s := range []string{"500", "500x", "0x300", "500x300 colour=red", `500x300 label="door`, "500x300 qty=0",
+		"80x50 form=circle", "d80 band=t:1", "50x30 radius=20", "e80x50 radius=5", "50x30 art=logo.gif"} {
+		if _, err := ParseSpec(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package packong
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// names of price lines added by a price list
+const (
+	LineDiscount = "discount"
+	LineMinimum  = "minimum"
+)
+
+// key of price list entries applying to any material or group
+const anyKey = "*"
+
+// Tier is a rate per square meter applying when area reaches From
+type Tier struct {
+	From float64 `json:"from"`
+	Rate float64 `json:"rate"`
+}
+
+// Discount lowers a price by a percent or by a fixed amount
+type Discount struct {
+	Percent float64 `json:"percent"`
+	Fixed   float64 `json:"fixed"`
+}
+
+// PriceList holds tiered rates and minimum charges per material and discounts
+// per customer group; "*" entries apply when nothing more specific is found
+type PriceList struct {
+	Tiers     map[string][]Tier   `json:"tiers"`
+	Minimum   map[string]float64  `json:"minimum"`
+	Discounts map[string]Discount `json:"discounts"`
+}
+
+// LoadPriceList reads a json price list
+func LoadPriceList(r io.Reader) (*PriceList, error) {
+	pl := &PriceList{}
+	if err := json.NewDecoder(r).Decode(pl); err != nil {
+		return nil, err
+	}
+	for material, tiers := range pl.Tiers {
+		for _, t := range tiers {
+			if t.From < 0 || t.Rate < 0 {
+				return nil, fmt.Errorf("price list: negative tier for %q", material)
+			}
+		}
+		sort.Slice(tiers, func(i, j int) bool {
+			return tiers[i].From < tiers[j].From
+		})
+	}
+	return pl, nil
+}
+
+// tier finds the tier matching area; ok is false when material has no tiers
+func (pl *PriceList) tier(material string, area float64) (t Tier, upto float64, ok bool) {
+	tiers, found := pl.Tiers[material]
+	if !found {
+		tiers, found = pl.Tiers[anyKey]
+	}
+	if !found || len(tiers) == 0 || area < tiers[0].From {
+		return Tier{}, 0, false
+	}
+	i := sort.Search(len(tiers), func(i int) bool {
+		return tiers[i].From > area
+	})
+	// 0 for upto means no upper bound
+	if i < len(tiers) {
+		upto = tiers[i].From
+	}
+	return tiers[i-1], upto, true
+}
+
+func (pl *PriceList) minimum(material string) float64 {
+	if m, ok := pl.Minimum[material]; ok {
+		return m
+	}
+	return pl.Minimum[anyKey]
+}
+
+func (pl *PriceList) discount(group string) (Discount, bool) {
+	if d, ok := pl.Discounts[group]; ok {
+		return d, true
+	}
+	d, ok := pl.Discounts[anyKey]
+	return d, ok
+}
+
+// TieredPrice is a standard price whose used material rate comes from a
+// price list; the rate of the tier reached by boxes area applies to all of it
+type TieredPrice struct {
+	StandardPrice
+	List     *PriceList
+	Material string
+	Group    string
+}
+
+// Price implements PriceModel
+func (tp TieredPrice) Price(rep *Report) Breakdown {
+	sp := tp.StandardPrice
+	t, upto, hasTier := tp.List.tier(tp.Material, rep.BoxesArea)
+	if hasTier {
+		sp.Mu = t.Rate
+	}
+	b := sp.Price(rep)
+	if hasTier {
+		for i := range b {
+			if b[i].Name != LineMaterial {
+				continue
+			}
+			b[i].Note = fmt.Sprintf("%v+ m2 at %.2f", t.From, t.Rate)
+			if upto > 0 {
+				b[i].Note = fmt.Sprintf("%v-%v m2 at %.2f", t.From, upto, t.Rate)
+			}
+		}
+	}
+
+	if d, ok := tp.List.discount(tp.Group); ok {
+		amount := b.Total()*d.Percent/100 + d.Fixed
+		if amount > 0 {
+			b = append(b, PriceLine{Name: LineDiscount, Amount: -amount, Note: tp.Group})
+		}
+	}
+
+	if min := tp.List.minimum(tp.Material); b.Total() < min {
+		b = append(b, PriceLine{Name: LineMinimum, Amount: min - b.Total(), Note: fmt.Sprintf("minimum order %.2f", min)})
+	}
+
+	return b
+}
+
+// PriceList prices used material from pl according to op's material and customer group
+func (op *Op) PriceList(pl *PriceList, group string) *Op {
+	op.list = pl
+	op.group = group
+	return op
+}
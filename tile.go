@@ -0,0 +1,164 @@
+package packong
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/innermond/packong/internal/svg"
+)
+
+// Panel is a part of a piece too large for sheet; neighbour panels overlap
+// so they can be welded or joined like wallpaper
+type Panel struct {
+	// Number orders panels row by row, 1 based, out of Count
+	Number int `json:"number"`
+	Count  int `json:"count"`
+	// Row and Col place panel in its piece, 1 based
+	Row int `json:"row"`
+	Col int `json:"col"`
+	// overlap shared with neighbours on every edge; outer edges have none
+	Top    float64 `json:"top,omitempty"`
+	Right  float64 `json:"right,omitempty"`
+	Bottom float64 `json:"bottom,omitempty"`
+	Left   float64 `json:"left,omitempty"`
+	// where panel starts on its piece
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+
+	// size of whole piece
+	w, h float64
+}
+
+func (p *Panel) String() string {
+	return fmt.Sprintf("%d/%d", p.Number, p.Count)
+}
+
+// overlaps gives overlap of edges as they lay on sheet; rotation turns clockwise like edges of banding do
+func (p *Panel) overlaps(rotated bool) (top, right, bottom, left float64) {
+	if rotated {
+		return p.Left, p.Top, p.Right, p.Bottom
+	}
+	return p.Top, p.Right, p.Bottom, p.Left
+}
+
+// Tiling splits pieces too large for sheet into panels overlapping by overlap
+func (op *Op) Tiling(overlap float64) *Op {
+	op.tiling = true
+	op.overlap = overlap
+	return op
+}
+
+// tiled is a panel sized piece
+type tiled struct {
+	w, h  float64
+	panel *Panel
+	band  *Banding
+}
+
+// tile splits a w by h piece, which may be rotated, into panels fitting sheet;
+// a piece fitting as it is gives itself
+func (op *Op) tile(w, h float64, rotate bool, b *Banding, bleed float64) ([]tiled, error) {
+	// room of a panel, cut width and bleed included
+	mw, mh := op.room()
+	mw -= op.cutwidth + 2*bleed
+	mh -= op.cutwidth + 2*bleed
+	cw, ch := b.cutSize(w, h)
+	if !op.tiling || cw <= mw && ch <= mh || rotate && ch <= mw && cw <= mh {
+		return []tiled{{w, h, nil, b}}, nil
+	}
+
+	split := func(l, room float64) (int, float64, error) {
+		if l <= room {
+			return 1, l, nil
+		}
+		if room <= op.overlap {
+			return 0, 0, fmt.Errorf("overlap %v leaves no room for panels on a sheet %v wide", op.overlap, room)
+		}
+		n := int(math.Ceil((l - op.overlap) / (room - op.overlap)))
+		return n, (l + float64(n-1)*op.overlap) / float64(n), nil
+	}
+	// cut size is what is packed, so it is split; tape is added back to outer panels
+	cols, pw, err := split(cw, mw)
+	if err != nil {
+		return nil, err
+	}
+	rows, ph, err := split(ch, mh)
+	if err != nil {
+		return nil, err
+	}
+
+	tt := []tiled{}
+	for r := 1; r <= rows; r++ {
+		for c := 1; c <= cols; c++ {
+			p := &Panel{
+				Number: len(tt) + 1, Count: rows * cols, Row: r, Col: c,
+				X: float64(c-1) * (pw - op.overlap), Y: float64(r-1) * (ph - op.overlap),
+				w: w, h: h,
+			}
+			if r > 1 {
+				p.Top = op.overlap
+			}
+			if r < rows {
+				p.Bottom = op.overlap
+			}
+			if c > 1 {
+				p.Left = op.overlap
+			}
+			if c < cols {
+				p.Right = op.overlap
+			}
+			// only outer edges keep their tape
+			var pb *Banding
+			fw, fh := pw, ph
+			if b != nil {
+				band := *b
+				band.Top = b.Top && r == 1
+				band.Bottom = b.Bottom && r == rows
+				band.Left = b.Left && c == 1
+				band.Right = b.Right && c == cols
+				pb = &band
+				fw += b.Thickness * count(band.Left, band.Right)
+				fh += b.Thickness * count(band.Top, band.Bottom)
+				// panels past the first start after tape of piece's first edge
+				if c > 1 && b.Left {
+					p.X += b.Thickness
+				}
+				if r > 1 && b.Top {
+					p.Y += b.Thickness
+				}
+			}
+			tt = append(tt, tiled{fw, fh, p, pb})
+		}
+	}
+	return tt, nil
+}
+
+// panelMarks gives overlap zones as x, y, w, h and where panel numbers go
+func (op *Op) panelMarks(sh sheet) ([][4]float64, []svg.PanelMark) {
+	zones := [][4]float64{}
+	marks := []svg.PanelMark{}
+	for _, box := range sh.boxes {
+		pc := sh.pieces[box]
+		if pc == nil || pc.Panel == nil {
+			continue
+		}
+		// cut width is not part of the piece
+		x, y := box.X, box.Y
+		w, h := box.W-0.5*op.cutwidth, box.H-0.5*op.cutwidth
+		top, right, bottom, left := pc.Panel.overlaps(box.Rotated)
+		if top > 0 {
+			zones = append(zones, [4]float64{x, y, w, top})
+		}
+		if right > 0 {
+			zones = append(zones, [4]float64{x + w - right, y, right, h})
+		}
+		if bottom > 0 {
+			zones = append(zones, [4]float64{x, y + h - bottom, w, bottom})
+		}
+		if left > 0 {
+			zones = append(zones, [4]float64{x, y, left, h})
+		}
+		marks = append(marks, svg.PanelMark{X: x + w/2, Y: y + h/2, Size: math.Min(w, h) / 5, Text: pc.Panel.String()})
+	}
+	return zones, marks
+}
@@ -0,0 +1,113 @@
+package packong
+
+import (
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestTiling(t *testing.T) {
+	op := NewOp(1370, 2500, []string{"3000x3000 label=wall band=tblr:1", "500x500"}, "mm").Tiling(30)
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 columns by 2 rows of panels and a whole piece
+	if len(boxes) != 7 {
+		t.Fatalf("got %d boxes, expected 7", len(boxes))
+	}
+	for _, b := range boxes {
+		pc := op.pieces[b]
+		if pc.Panel == nil {
+			if pc.Label != "500x500" {
+				t.Errorf("%s: expected a panel", pc.Label)
+			}
+			continue
+		}
+		p := pc.Panel
+		// cut size of 2998 by 2998 is split, joins overlapping
+		if cw, ch := pc.Banding.cutSize(pc.W, pc.H); math.Abs(cw-(2998+2*30)/3.0) > 1e-9 || ch != 1514 || p.Count != 6 {
+			t.Errorf("panel %s: got cut size %vx%v out of %d", p, cw, ch, p.Count)
+		}
+		if want := 1514 + count(p.Row == 1, p.Row == 2); pc.H != want {
+			t.Errorf("panel %s: got height %v, expected %v with its tape", p, pc.H, want)
+		}
+		if p.Number != (p.Row-1)*3+p.Col || pc.Label != "wall "+p.String() {
+			t.Errorf("panel %s: unexpected number or label %q", p, pc.Label)
+		}
+		// joins overlap, outer edges keep tape
+		if (p.Left > 0) != (p.Col > 1) || (p.Right > 0) != (p.Col < 3) || (p.Top > 0) != (p.Row > 1) || (p.Bottom > 0) != (p.Row < 2) {
+			t.Errorf("panel %s: got overlaps %+v", p, *p)
+		}
+		if pc.Banding.Left != (p.Col == 1) || pc.Banding.Bottom != (p.Row == 2) {
+			t.Errorf("panel %s: got banding %s", p, pc.Banding)
+		}
+	}
+
+	if _, err := NewOp(1370, 2500, []string{"3000x3000"}, "mm").Tiling(2000).BoxesFromString(); err == nil {
+		t.Error("expected overlap wider than sheet to fail")
+	}
+}
+
+func TestTilingLegend(t *testing.T) {
+	// legend of a panel and small pieces around it needs all its rows
+	legend := func(dims ...string) []string {
+		op := NewOp(1370, 2500, dims, "mm").Tiling(30).Outname("wall").Legend(true)
+		boxes, err := op.BoxesFromString()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, outs, err := op.Fit([][]*pak.Box{boxes}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ss := []string{}
+		for _, out := range outs {
+			for _, r := range out {
+				b, err := ioutil.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s := string(b)
+				ss = append(ss, s[strings.Index(s, `id="title"`):])
+			}
+		}
+		return ss
+	}
+
+	rect := regexp.MustCompile(`<rect x="[^"]*" y="([^"]*)" width="[^"]*" height="([^"]*)"`)
+	full := false
+	for _, title := range legend("3000x3000 band=tblr:1", "100x100x40", "300x300x40") {
+		rr := rect.FindAllStringSubmatch(title, -1)
+		// block's border, then a rect between title and legend, then a swatch per row
+		full = full || len(rr) == 2+6
+		top, _ := strconv.ParseFloat(rr[0][1], 64)
+		h, _ := strconv.ParseFloat(rr[0][2], 64)
+		for _, m := range rr[2:] {
+			y, _ := strconv.ParseFloat(m[1], 64)
+			sh, _ := strconv.ParseFloat(m[2], 64)
+			if y < top || y+sh > top+h+1e-6 {
+				t.Errorf("legend row at %v high %v is out of block %v..%v", y, sh, top, top+h)
+			}
+		}
+	}
+	if !full {
+		t.Error("expected a sheet showing every fill")
+	}
+
+	// only fills drawn are explained
+	title := legend("500x500")[0]
+	for _, label := range []string{"top edge", "left edge", "other boxes", "banded edge", "panel overlap"} {
+		if strings.Contains(title, label) {
+			t.Errorf("legend lists %q not drawn", label)
+		}
+	}
+	if !strings.Contains(title, "first box") {
+		t.Error("legend misses first box")
+	}
+}
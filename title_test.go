@@ -0,0 +1,45 @@
+package packong
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestTitleEscaped(t *testing.T) {
+	job, material := `Smith & Sons <kitchen>`, `mdf "18"`
+	op := NewOp(1000, 2000, []string{"300x200"}, "mm").Outname("x").Title(job, material)
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, outs, err := op.Fit([][]*pak.Box{boxes}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range outs {
+		for fn, r := range out {
+			// sheet stays well formed xml and tells text as given
+			text := ""
+			dec := xml.NewDecoder(r)
+			for {
+				tok, err := dec.Token()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", fn, err)
+				}
+				if cd, ok := tok.(xml.CharData); ok {
+					text += string(cd)
+				}
+			}
+			if !strings.Contains(text, job) || !strings.Contains(text, material) {
+				t.Errorf("%s: expected job and material written as given", fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package packong
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/innermond/pak"
+)
+
+// reasons a piece is left out
+const (
+	// larger than sheet whichever way it is turned
+	UnfitTooLarge = "too_large"
+	// fits only turned but it must not be rotated
+	UnfitRotation = "rotation"
+	// fits only without margins, bleed and cut width
+	UnfitMargin = "margin"
+	// fits an empty sheet but packing stopped before placing it
+	UnfitStopped = "stopped"
+)
+
+// Unfit tells why a piece was left out and what would make it fit
+type Unfit struct {
+	Piece      *Piece `json:"piece"`
+	Reason     string `json:"reason"`
+	Note       string `json:"note"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// diagnose explains every box left out; pieces knows what boxes stand for
+func (op *Op) diagnose(boxes []*pak.Box, pieces map[*pak.Box]*Piece) []Unfit {
+	uu := []Unfit{}
+	// room an empty sheet offers
+	sw, sh := op.room()
+	fits := func(w, h float64) bool {
+		return w <= sw && h <= sh
+	}
+	for _, box := range boxes {
+		if pieces[box] == nil {
+			continue
+		}
+		for _, p := range pieces[box].members() {
+			// packed size; box itself may have been shrunk while packing
+			pw, ph := p.Banding.cutSize(p.W, p.H)
+			w, h := pw+2*p.Bleed+op.cutwidth, ph+2*p.Bleed+op.cutwidth
+
+			u := Unfit{Piece: p}
+			switch {
+			case fits(w, h) || box.CanRotate && fits(h, w):
+				u.Reason, u.Note = UnfitStopped, "fits an empty sheet but packing stopped when a sheet placed nothing"
+			case fits(h, w):
+				u.Reason, u.Note = UnfitRotation, "fits only turned, and rotation is not allowed"
+				u.Suggestion = "fits if rotation allowed"
+			case pw <= op.width && ph <= op.height || box.CanRotate && ph <= op.width && pw <= op.height:
+				u.Reason, u.Note = UnfitMargin, fmt.Sprintf("margins, bleed %v and cut width %v take the room it needs", p.Bleed, op.cutwidth)
+				u.Suggestion = "fits with smaller margins, bleed or cut width"
+			default:
+				u.Reason, u.Note = UnfitTooLarge, fmt.Sprintf("%vx%v is larger than sheet %vx%v", p.W, p.H, op.width, op.height)
+				u.Suggestion = op.needs(w, h, box.CanRotate)
+			}
+			uu = append(uu, u)
+		}
+	}
+	return uu
+}
+
+// needs suggests the smallest sheet taking a w by h box
+func (op *Op) needs(w, h float64, rotate bool) string {
+	up := func(v float64) float64 {
+		return math.Ceil(v*100) / 100
+	}
+	_, rh := op.room()
+	mw, mh := op.margins.Left+op.margins.Right, op.margins.Top+op.margins.Bottom
+	turns := [][2]float64{{w, h}}
+	if rotate {
+		turns = append(turns, [2]float64{h, w})
+	}
+	// a wider sheet is enough when length already suffices
+	wide := math.Inf(1)
+	for _, t := range turns {
+		if t[1] <= rh {
+			wide = math.Min(wide, t[0])
+		}
+	}
+	if !math.IsInf(wide, 1) {
+		return fmt.Sprintf("needs sheet ≥ %v wide", up(wide+mw))
+	}
+	t := turns[0]
+	if rotate && h < w {
+		// narrow side across keeps sheet narrow
+		t = turns[1]
+	}
+	// sheet is never suggested narrower than it is
+	return fmt.Sprintf("needs sheet ≥ %vx%v", up(math.Max(op.width, t[0]+mw)), up(t[1]+mh))
+}
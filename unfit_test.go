@@ -0,0 +1,36 @@
+package packong
+
+import (
+	"testing"
+
+	"github.com/innermond/pak"
+)
+
+func TestUnfit(t *testing.T) {
+	dd := []string{"100x100", "600x300x1xno", "1200x300", "300x2500", "400x1500 rotate=no"}
+	op := NewOp(500, 1000, dd, "mm")
+	boxes, err := op.BoxesFromString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, _, err := op.Fit([][]*pak.Box{boxes}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][2]string{
+		"600x300":  {UnfitRotation, "fits if rotation allowed"},
+		"1200x300": {UnfitTooLarge, "needs sheet ≥ 1200 wide"},
+		"300x2500": {UnfitTooLarge, "needs sheet ≥ 2500 wide"},
+		"400x1500": {UnfitTooLarge, "needs sheet ≥ 500x1500"},
+	}
+	if len(rep.Unfit) != len(want) {
+		t.Fatalf("got %d unfit pieces, expected %d", len(rep.Unfit), len(want))
+	}
+	for _, u := range rep.Unfit {
+		w, ok := want[u.Piece.Label]
+		if !ok || u.Reason != w[0] || u.Suggestion != w[1] {
+			t.Errorf("%s: got %s %q, expected %s %q", u.Piece.Label, u.Reason, u.Suggestion, w[0], w[1])
+		}
+	}
+}
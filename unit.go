@@ -0,0 +1,98 @@
+package packong
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Unit is a measurement unit for lengths
+type Unit struct {
+	Name string
+	// PerMeter is how many units make a meter
+	PerMeter float64
+	// SVG tells the unit is understood by svg as is
+	SVG bool
+}
+
+var (
+	unitsMu sync.RWMutex
+	units   = map[string]Unit{
+		"mm": {"mm", 1000, true},
+		"cm": {"cm", 100, true},
+		"m":  {"m", 1, false},
+		"in": {"in", 1 / 0.0254, true},
+		"ft": {"ft", 1 / 0.3048, false},
+	}
+)
+
+// RegisterUnit adds a unit, or replaces one having the same name
+func RegisterUnit(u Unit) error {
+	if u.Name == "" || u.PerMeter <= 0 {
+		return fmt.Errorf("unit %q needs a name and a positive size", u.Name)
+	}
+	unitsMu.Lock()
+	defer unitsMu.Unlock()
+	units[u.Name] = u
+	return nil
+}
+
+// LookupUnit finds a registered unit by its name
+func LookupUnit(name string) (Unit, error) {
+	unitsMu.RLock()
+	defer unitsMu.RUnlock()
+	u, ok := units[name]
+	if !ok {
+		return Unit{}, fmt.Errorf("unknown unit %q", name)
+	}
+	return u, nil
+}
+
+// svgUnit gives a unit svg understands and how many of it make one u
+func (u Unit) svgUnit() (string, float64) {
+	if u.SVG {
+		return u.Name, 1
+	}
+	return "mm", 1000 / u.PerMeter
+}
+
+// parseLength reads a length written as a decimal, a fraction or a whole
+// number followed by a fraction, like 24.5, 1/2, "24 1/2" or 24-1/2
+func parseLength(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	whole, frac := "", s
+	if i := strings.LastIndexAny(s, " -"); i > 0 {
+		whole, frac = strings.TrimSpace(s[:i]), s[i+1:]
+	}
+
+	v := 0.0
+	if whole != "" {
+		w, err := strconv.ParseFloat(whole, 64)
+		if err != nil {
+			return 0, err
+		}
+		v = w
+	}
+
+	num, den := frac, ""
+	if i := strings.IndexByte(frac, '/'); i >= 0 {
+		num, den = frac[:i], frac[i+1:]
+	} else if whole != "" {
+		return 0, fmt.Errorf("length %q: expected a fraction after %s", s, whole)
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	if den != "" {
+		d, err := strconv.ParseFloat(den, 64)
+		if err != nil {
+			return 0, err
+		} else if d == 0 {
+			return 0, fmt.Errorf("length %q divides by zero", s)
+		}
+		n /= d
+	}
+	return v + n, nil
+}
@@ -0,0 +1,40 @@
+package packong
+
+import "testing"
+
+func TestParseLength(t *testing.T) {
+	type test struct {
+		s    string
+		want float64
+	}
+	tt := []test{
+		{"24", 24},
+		{"24.5", 24.5},
+		{"1/2", 0.5},
+		{"24 1/2", 24.5},
+		{"24-3/4", 24.75},
+	}
+	for _, tc := range tt {
+		got, err := parseLength(tc.s)
+		if err != nil {
+			t.Errorf("%q: %v", tc.s, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: got %v, expected %v", tc.s, got, tc.want)
+		}
+	}
+
+	for _, s := range []string{"", "a", "1/0", "24 5", "24 1/x"} {
+		if _, err := parseLength(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestUnknownUnit(t *testing.T) {
+	op := NewOp(100, 100, []string{"10x10"}, "yd")
+	if _, err := op.BoxesFromString(); err == nil {
+		t.Error("expected error for unknown unit")
+	}
+}
@@ -0,0 +1,39 @@
+package packong
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Invalid is a problem found in a dimension entry
+type Invalid struct {
+	// Entry is index of entry in dimensions
+	Entry int `json:"entry"`
+	// Value is entry as received
+	Value string `json:"value"`
+	// Field names what is wrong: width, height, qty, rotate, band, bleed, label, material, priority
+	// or entry when entry as a whole can't be read
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError lists every problem of dimension entries
+type ValidationError []Invalid
+
+func (e ValidationError) Error() string {
+	ss := []string{}
+	for _, inv := range e {
+		ss = append(ss, fmt.Sprintf("dimensions[%d] %q: %s %s", inv.Entry, inv.Value, inv.Field, inv.Reason))
+	}
+	return strings.Join(ss, "; ")
+}
+
+// has tells field of entry is already known as wrong
+func (e ValidationError) has(entry int, field string) bool {
+	for _, inv := range e {
+		if inv.Entry == entry && inv.Field == field {
+			return true
+		}
+	}
+	return false
+}